@@ -0,0 +1,59 @@
+package bracket
+
+import (
+	"context"
+	"sync"
+)
+
+const defaultBatchConcurrency = 4
+
+// BatchOptions configures FetchBrackets.
+type BatchOptions struct {
+	// Concurrency is the most brackets fetched at once. Defaults to 4.
+	Concurrency int
+}
+
+// BatchResult is the outcome of fetching one URL with FetchBrackets.
+type BatchResult struct {
+	URL     string
+	Bracket *Bracket
+	Err     error
+}
+
+// FetchBrackets fetches many brackets in parallel using a bounded pool of
+// workers. Requests still go through the client's rate limits, so a large
+// batch against one service is spread out rather than rejected.
+//
+// The results are in the same order as urls. A failed fetch is reported
+// in its result's Err and doesn't stop the rest of the batch. opts may be
+// nil to use the defaults.
+func (c *Client) FetchBrackets(ctx context.Context, urls []string, opts *BatchOptions) []BatchResult {
+	concurrency := defaultBatchConcurrency
+	if opts != nil && opts.Concurrency > 0 {
+		concurrency = opts.Concurrency
+	}
+	if concurrency > len(urls) {
+		concurrency = len(urls)
+	}
+
+	results := make([]BatchResult, len(urls))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				b, err := c.FetchBracketContext(ctx, urls[i])
+				results[i] = BatchResult{urls[i], b, err}
+			}
+		}()
+	}
+	for i := range urls {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	return results
+}
@@ -0,0 +1,64 @@
+package bracket
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFetchBrackets(t *testing.T) {
+	var inFlight, maxInFlight int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if n <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+
+		if strings.Contains(r.URL.Path, "missing") {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"tournament":{"name":"` + strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/tournaments/"), ".json") + `"}}`))
+	}))
+	defer server.Close()
+
+	c := New(WithChallongeBaseURL(server.URL))
+	urls := []string{
+		"http://challonge.com/one",
+		"http://challonge.com/missing",
+		"https://example.com/unsupported",
+		"http://challonge.com/two",
+		"http://challonge.com/three",
+		"http://challonge.com/four",
+	}
+	results := c.FetchBrackets(context.Background(), urls, &BatchOptions{Concurrency: 2})
+
+	assert.Len(t, results, len(urls))
+	for i, r := range results {
+		assert.Equal(t, urls[i], r.URL)
+	}
+	assert.Equal(t, "one", results[0].Bracket.Name)
+	assert.True(t, errors.Is(results[1].Err, ErrNotFound))
+	assert.True(t, errors.Is(results[2].Err, ErrUnsupportedURL))
+	assert.Equal(t, "two", results[3].Bracket.Name)
+	assert.Equal(t, "three", results[4].Bracket.Name)
+	assert.Equal(t, "four", results[5].Bracket.Name)
+	assert.True(t, maxInFlight <= 2, "max in flight %d", maxInFlight)
+}
+
+func TestFetchBracketsEmpty(t *testing.T) {
+	c := New()
+	assert.Empty(t, c.FetchBrackets(context.Background(), nil, nil))
+}
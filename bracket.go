@@ -1,12 +1,37 @@
 package bracket
 
-import "time"
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
 
 // Client holds API keys and data necessary to make
 // calls to different bracket services.
 type Client struct {
-	challongeUser   string
-	challongeAPIKey string
+	httpClient   *http.Client
+	userAgent    string
+	retryPolicy  RetryPolicy
+	rateLimiters map[string]*rateLimiter
+	cachePolicy  CachePolicy
+
+	challongeUser       string
+	challongeAPIKey     string
+	challongeBaseURL    string
+	challongeAPIVersion ChallongeAPIVersion
+	challongeTokens     *challongeTokenSource
+	challongeTokenURL   string
+	// challongeNameSeparators split sponsor prefixes from gamer tags in
+	// Challonge display names.
+	challongeNameSeparators []string
+
+	smashGGBaseURL string
+
+	startGGToken    string
+	startGGEndpoint string
+
+	registry providerRegistry
 }
 
 // Bracket represents a tournament bracket.
@@ -15,9 +40,24 @@ type Bracket struct {
 	Name      string
 	StartedAt *time.Time
 	UpdatedAt *time.Time
-	State     string
+	State     BracketState
 	Players   []*Player
 	Matches   []*Match
+
+	// Format is the kind of bracket, and FormatSettings the settings
+	// specific to it.
+	Format         BracketFormat
+	FormatSettings FormatSettings
+
+	// RawState is the state exactly as the service returned it.
+	RawState string
+
+	// Tournament, Event and Phase are the tournament, event and phase the
+	// bracket belongs to, when the service organizes brackets that way.
+	// Only their own details are set, not their events, phases or groups.
+	Tournament *Tournament
+	Event      *TournamentEvent
+	Phase      *Phase
 }
 
 // Player represents a participant in a tournament.
@@ -26,6 +66,26 @@ type Player struct {
 	Name string
 	Seed int
 	Rank int
+
+	// GamerTag is the player's tag without their sponsor Prefix, such as
+	// "Dr. Pizza" for "DPS|Dr. Pizza".
+	GamerTag string
+	Prefix   string
+	RealName string
+	// Country and Region, the player's state or province, are as the
+	// service gives them, which may be a name or a code.
+	Country string
+	Region  string
+	// AccountID identifies the player's account with the service, which
+	// stays the same across tournaments. It is empty for players who
+	// entered without an account.
+	AccountID string
+
+	// Members are the people on a team, such as a doubles pair, each with
+	// their own identity. It is empty for players who entered alone.
+	// Members' IDs identify them within the tournament, and don't appear
+	// in matches.
+	Members []*Player
 }
 
 // Match represents a match in a tournament bracket.
@@ -35,31 +95,97 @@ type Match struct {
 	StartedAt            *time.Time
 	UpdatedAt            *time.Time
 	Round                int
-	State                string
+	State                MatchState
 	Player1ID            string
 	Player1PrereqMatchID *string
 	Player2ID            string
 	Player2PrereqMatchID *string
 	WinnerID             string
 	LoserID              string
-	Player1Score         int
-	Player2Score         int
+	// Player1Score and Player2Score are the totals across all games.
+	Player1Score int
+	Player2Score int
+	// Games holds the result of each game, where the service reports them.
+	Games []Game
+	// RawScores is the score string exactly as the service returned it,
+	// for services that report scores that way. If it can't be parsed,
+	// ScoresError says why, and the match has no Games and its scores are
+	// zero.
+	RawScores   string
+	ScoresError *ScoresError
+	// Side is the part of the bracket the match is in, and RoundLabel a
+	// name for its round such as "Losers Round 3". Both are only set on
+	// matches fetched as part of a bracket.
+	Side       MatchSide
+	RoundLabel string
+	// Outcome is how the match was decided, so byes and DQs can be told
+	// apart from matches that were played.
+	Outcome MatchOutcome
+	// RawState is the state exactly as the service returned it.
+	RawState string
+}
+
+// Game represents a single game within a match.
+type Game struct {
+	// Number is the game's position in the match, starting at 1.
+	Number int
+	// WinnerID is "0" if the game has no winner, such as a tie.
+	WinnerID     string
+	Player1Score int
+	Player2Score int
+	// Player1Characters and Player2Characters are the characters each
+	// player used, by name where the service provides one and by ID
+	// otherwise.
+	Player1Characters []string
+	Player2Characters []string
+	Stage             string
+}
+
+// New instantiates an API client configured by the given options.
+func New(opts ...Option) *Client {
+	c := &Client{
+		httpClient:              http.DefaultClient,
+		userAgent:               defaultUserAgent,
+		challongeAPIVersion:     ChallongeV1,
+		challongeTokenURL:       defaultChallongeTokenURL,
+		challongeNameSeparators: append([]string(nil), DefaultNameSeparators...),
+		smashGGBaseURL:          defaultSmashGGBaseURL,
+		startGGEndpoint:         defaultStartGGEndpoint,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.challongeBaseURL == "" {
+		c.challongeBaseURL = defaultChallongeBaseURL
+		if c.challongeAPIVersion == ChallongeV2 {
+			c.challongeBaseURL = defaultChallongeV2BaseURL
+		}
+	}
+	c.RegisterProvider(&challongeProvider{c}, 0)
+	c.RegisterProvider(&smashGGProvider{c}, 0)
+	c.RegisterProvider(&startGGProvider{c}, 0)
+	return c
 }
 
 // NewClient provides a convenient way to instantiate
-// an API client.
+// an API client with Challonge credentials. It is equivalent to
+// New(WithChallongeCredentials(challongeUser, challongeAPIKey)).
 func NewClient(challongeUser, challongeAPIKey string) *Client {
-	return &Client{challongeUser, challongeAPIKey}
+	return New(WithChallongeCredentials(challongeUser, challongeAPIKey))
 }
 
 // FetchBracket takes a URL, calls the appropriate web service for the URL,
 // and returns a bracket.
-func (c Client) FetchBracket(url string) (*Bracket, error) {
-	if isChallongeURL(url) {
-		return fetchChallongeBracket(c.challongeUser, c.challongeAPIKey, url)
-	}
-	if isSmashGGURL(url) {
-		return fetchSmashGGBracket(url)
+func (c *Client) FetchBracket(url string) (*Bracket, error) {
+	return c.FetchBracketContext(context.Background(), url)
+}
+
+// FetchBracketContext is like FetchBracket, but cancels any outstanding
+// requests to the web service once ctx is done.
+func (c *Client) FetchBracketContext(ctx context.Context, url string) (*Bracket, error) {
+	p := c.ProviderFor(url)
+	if p == nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedURL, url)
 	}
-	return nil, nil
+	return p.FetchBracket(ctx, url)
 }
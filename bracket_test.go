@@ -0,0 +1,31 @@
+package bracket
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFetchBracketContextCanceled(t *testing.T) {
+	c := NewClient("", "")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for _, url := range []string{
+		"http://challonge.com/xyfuz5c3",
+		"https://smash.gg/tournament/super-smash-sundays-48/brackets/14221/50133/165583",
+	} {
+		b, err := c.FetchBracketContext(ctx, url)
+		assert.Nil(t, b)
+		assert.True(t, errors.Is(err, context.Canceled), "%s: %v", url, err)
+	}
+}
+
+func TestFetchBracketUnsupportedURL(t *testing.T) {
+	c := NewClient("", "")
+	b, err := c.FetchBracket("https://example.com/bracket")
+	assert.Nil(t, b)
+	assert.True(t, errors.Is(err, ErrUnsupportedURL))
+}
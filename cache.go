@@ -0,0 +1,296 @@
+package bracket
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CachedResponse is a raw API response stored in a Cache.
+type CachedResponse struct {
+	Body []byte
+	// ETag and LastModified hold the validators the web service sent
+	// with the response, if any.
+	ETag         string
+	LastModified string
+	// StoredAt is when the response was fetched or last revalidated.
+	StoredAt time.Time
+}
+
+// Cache stores raw API responses keyed by API URL, plus a fingerprint of
+// the client's credentials if it has any, so clients with different
+// credentials don't share responses. Implementations must be safe for
+// concurrent use.
+type Cache interface {
+	// Get returns the response stored under key, if any.
+	Get(key string) (*CachedResponse, bool)
+	// Set stores a response under key, replacing any existing one.
+	Set(key string, resp *CachedResponse)
+	// Delete removes any response stored under key.
+	Delete(key string)
+}
+
+// CacheStatus describes how a request was served with respect to the cache.
+type CacheStatus int
+
+const (
+	// CacheMiss means the response was fetched from the web service.
+	CacheMiss CacheStatus = iota
+	// CacheHit means the response was served from the cache without
+	// contacting the web service.
+	CacheHit
+	// CacheRevalidated means the web service confirmed, with a conditional
+	// request, that the cached response was still current.
+	CacheRevalidated
+)
+
+func (s CacheStatus) String() string {
+	switch s {
+	case CacheHit:
+		return "hit"
+	case CacheRevalidated:
+		return "revalidated"
+	}
+	return "miss"
+}
+
+// CacheEvent reports how a single API request used the cache.
+type CacheEvent struct {
+	Provider string
+	URL      string
+	Status   CacheStatus
+}
+
+// CachePolicy configures response caching for a Client.
+type CachePolicy struct {
+	// Cache stores the responses.
+	Cache Cache
+	// TTL is how long a response without an ETag or Last-Modified header
+	// is served from the cache before being fetched again. Responses with
+	// either header are always revalidated with a conditional request.
+	TTL time.Duration
+	// OnLookup, if set, is called after every cached request.
+	OnLookup func(CacheEvent)
+}
+
+// WithCache caches raw API responses according to policy.
+func WithCache(policy CachePolicy) Option {
+	return func(c *Client) {
+		c.cachePolicy = policy
+	}
+}
+
+func (c *Client) getCached(ctx context.Context, r *apiRequest) ([]byte, error) {
+	policy := c.cachePolicy
+	report := func(status CacheStatus) {
+		if policy.OnLookup != nil {
+			policy.OnLookup(CacheEvent{r.Provider, r.URL, status})
+		}
+	}
+
+	key := c.cacheKey(r.URL)
+	cached, ok := policy.Cache.Get(key)
+	conditional := ok && (cached.ETag != "" || cached.LastModified != "")
+	if ok && !conditional && time.Since(cached.StoredAt) < policy.TTL {
+		report(CacheHit)
+		return cached.Body, nil
+	}
+
+	req := *r
+	if conditional {
+		req.Setup = func(httpReq *http.Request) {
+			if r.Setup != nil {
+				r.Setup(httpReq)
+			}
+			if cached.ETag != "" {
+				httpReq.Header.Set("If-None-Match", cached.ETag)
+			}
+			if cached.LastModified != "" {
+				httpReq.Header.Set("If-Modified-Since", cached.LastModified)
+			}
+		}
+	}
+	resp, err := c.do(ctx, &req)
+	if err != nil {
+		return nil, err
+	}
+
+	if conditional && resp.StatusCode == http.StatusNotModified {
+		refreshed := *cached
+		refreshed.StoredAt = time.Now()
+		policy.Cache.Set(key, &refreshed)
+		report(CacheRevalidated)
+		return cached.Body, nil
+	}
+
+	policy.Cache.Set(key, &CachedResponse{
+		Body:         resp.Body,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		StoredAt:     time.Now(),
+	})
+	report(CacheMiss)
+	return resp.Body, nil
+}
+
+// cacheKey is the key a response from url is cached under. The secrets are
+// hashed, so they aren't stored in the cache.
+func (c *Client) cacheKey(url string) string {
+	var creds []string
+	creds = append(creds, c.challongeUser, c.challongeAPIKey, c.startGGToken)
+	if s := c.challongeTokens; s != nil {
+		creds = append(creds, s.clientID, s.clientSecret)
+		if s.clientID == "" {
+			creds = append(creds, s.token)
+		}
+	}
+	if strings.Join(creds, "") == "" {
+		return url
+	}
+	sum := sha256.Sum256([]byte(strings.Join(creds, "\x00")))
+	return url + "#" + hex.EncodeToString(sum[:8])
+}
+
+// MemoryCache is an in-memory Cache that evicts the least recently used
+// response once it holds more than a fixed number of entries.
+type MemoryCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	order      *list.List
+	entries    map[string]*list.Element
+}
+
+type memoryCacheEntry struct {
+	key  string
+	resp *CachedResponse
+}
+
+// NewMemoryCache returns a MemoryCache holding at most maxEntries
+// responses. A maxEntries of 0 or less means no limit.
+func NewMemoryCache(maxEntries int) *MemoryCache {
+	return &MemoryCache{
+		maxEntries: maxEntries,
+		order:      list.New(),
+		entries:    make(map[string]*list.Element),
+	}
+}
+
+// Get implements Cache.
+func (m *MemoryCache) Get(key string) (*CachedResponse, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, ok := m.entries[key]
+	if !ok {
+		return nil, false
+	}
+	m.order.MoveToFront(e)
+	return e.Value.(*memoryCacheEntry).resp, true
+}
+
+// Set implements Cache.
+func (m *MemoryCache) Set(key string, resp *CachedResponse) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if e, ok := m.entries[key]; ok {
+		e.Value.(*memoryCacheEntry).resp = resp
+		m.order.MoveToFront(e)
+		return
+	}
+	m.entries[key] = m.order.PushFront(&memoryCacheEntry{key, resp})
+	if m.maxEntries > 0 && m.order.Len() > m.maxEntries {
+		oldest := m.order.Back()
+		m.order.Remove(oldest)
+		delete(m.entries, oldest.Value.(*memoryCacheEntry).key)
+	}
+}
+
+// Delete implements Cache.
+func (m *MemoryCache) Delete(key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if e, ok := m.entries[key]; ok {
+		m.order.Remove(e)
+		delete(m.entries, key)
+	}
+}
+
+// Len returns the number of responses in the cache.
+func (m *MemoryCache) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.order.Len()
+}
+
+// DiskCache is a Cache that stores each response as a file in a directory,
+// so cached responses survive restarts. It is best-effort: responses that
+// can't be read or written are treated as absent.
+type DiskCache struct {
+	dir string
+}
+
+// NewDiskCache returns a DiskCache storing responses in dir, which is
+// created if it doesn't exist.
+func NewDiskCache(dir string) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &DiskCache{dir}, nil
+}
+
+func (d *DiskCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(d.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// Get implements Cache.
+func (d *DiskCache) Get(key string) (*CachedResponse, bool) {
+	b, err := ioutil.ReadFile(d.path(key))
+	if err != nil {
+		return nil, false
+	}
+	var resp CachedResponse
+	if err := json.Unmarshal(b, &resp); err != nil {
+		return nil, false
+	}
+	return &resp, true
+}
+
+// Set implements Cache.
+func (d *DiskCache) Set(key string, resp *CachedResponse) {
+	b, err := json.Marshal(resp)
+	if err != nil {
+		return
+	}
+	// Write to a temporary file first so readers never see a partial entry
+	tmp, err := ioutil.TempFile(d.dir, "tmp-")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(b)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), d.path(key))
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+}
+
+// Delete implements Cache.
+func (d *DiskCache) Delete(key string) {
+	os.Remove(d.path(key))
+}
@@ -0,0 +1,167 @@
+package bracket
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	m := NewMemoryCache(2)
+	m.Set("a", &CachedResponse{Body: []byte("a")})
+	m.Set("b", &CachedResponse{Body: []byte("b")})
+	// Touch a so that b becomes the oldest
+	_, ok := m.Get("a")
+	assert.True(t, ok)
+	m.Set("c", &CachedResponse{Body: []byte("c")})
+
+	assert.Equal(t, 2, m.Len())
+	_, ok = m.Get("b")
+	assert.False(t, ok)
+	resp, ok := m.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, []byte("a"), resp.Body)
+
+	m.Delete("a")
+	_, ok = m.Get("a")
+	assert.False(t, ok)
+	assert.Equal(t, 1, m.Len())
+}
+
+func TestDiskCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bracket-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	d, err := NewDiskCache(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, ok := d.Get("https://api.challonge.com/v1/tournaments/xyfuz5c3.json")
+	assert.False(t, ok)
+
+	storedAt := time.Date(2016, 4, 2, 12, 0, 0, 0, time.UTC)
+	d.Set("https://api.challonge.com/v1/tournaments/xyfuz5c3.json", &CachedResponse{
+		Body:     []byte(`{"tournament":{}}`),
+		ETag:     `"abc"`,
+		StoredAt: storedAt,
+	})
+	resp, ok := d.Get("https://api.challonge.com/v1/tournaments/xyfuz5c3.json")
+	assert.True(t, ok)
+	assert.Equal(t, []byte(`{"tournament":{}}`), resp.Body)
+	assert.Equal(t, `"abc"`, resp.ETag)
+	assert.True(t, storedAt.Equal(resp.StoredAt))
+
+	d.Delete("https://api.challonge.com/v1/tournaments/xyfuz5c3.json")
+	_, ok = d.Get("https://api.challonge.com/v1/tournaments/xyfuz5c3.json")
+	assert.False(t, ok)
+}
+
+func TestCacheRevalidatesWithETag(t *testing.T) {
+	fixture, err := ioutil.ReadFile("testdata/challonge.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		w.Write(fixture)
+	}))
+	defer server.Close()
+
+	var statuses []CacheStatus
+	c := New(
+		WithChallongeBaseURL(server.URL),
+		WithCache(CachePolicy{
+			Cache: NewMemoryCache(10),
+			TTL:   time.Hour,
+			OnLookup: func(e CacheEvent) {
+				statuses = append(statuses, e.Status)
+			},
+		}),
+	)
+	for i := 0; i < 2; i++ {
+		b, err := c.FetchBracket("http://challonge.com/xyfuz5c3")
+		assert.NoError(t, err)
+		assert.Equal(t, "Missouri River Arcadian - The Sequel: Smash4 Top 16", b.Name)
+	}
+	// The ETag forces a conditional request even though the TTL hasn't passed
+	assert.Equal(t, 2, requests)
+	assert.Equal(t, []CacheStatus{CacheMiss, CacheRevalidated}, statuses)
+}
+
+func TestCacheFallsBackToTTL(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		assert.Empty(t, r.Header.Get("If-None-Match"))
+		assert.Empty(t, r.Header.Get("If-Modified-Since"))
+		w.Write([]byte(`{"entities":{}}`))
+	}))
+	defer server.Close()
+
+	cache := NewMemoryCache(10)
+	var statuses []CacheStatus
+	c := New(
+		WithSmashGGBaseURL(server.URL),
+		WithCache(CachePolicy{
+			Cache: cache,
+			TTL:   time.Hour,
+			OnLookup: func(e CacheEvent) {
+				statuses = append(statuses, e.Status)
+			},
+		}),
+	)
+	url := "https://smash.gg/tournament/super-smash-sundays-48/brackets/14221/50133/165583"
+	c.FetchBracket(url)
+	c.FetchBracket(url)
+	assert.Equal(t, 1, requests)
+
+	// Once the TTL has passed, the response is fetched again
+	resp, ok := cache.Get(getSmashGGAPIURL(server.URL+"/", url))
+	assert.True(t, ok)
+	resp.StoredAt = resp.StoredAt.Add(-2 * time.Hour)
+	c.FetchBracket(url)
+	assert.Equal(t, 2, requests)
+	assert.Equal(t, []CacheStatus{CacheMiss, CacheHit, CacheMiss}, statuses)
+}
+
+func TestCacheKeyIncludesCredentials(t *testing.T) {
+	url := "https://api.challonge.com/v1/tournaments/xyfuz5c3.json"
+	assert.Equal(t, url, New().cacheKey(url))
+
+	a := New(WithChallongeCredentials("user", "key1")).cacheKey(url)
+	b := New(WithChallongeCredentials("user", "key2")).cacheKey(url)
+	assert.NotEqual(t, a, b)
+	assert.NotContains(t, a, "key1")
+	assert.Equal(t, a, New(WithChallongeCredentials("user", "key1")).cacheKey(url))
+}
+
+func TestUnconditionalNotModifiedIsAnError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotModified)
+	}))
+	defer server.Close()
+
+	c := New(
+		WithChallongeBaseURL(server.URL),
+		WithCache(CachePolicy{Cache: NewMemoryCache(10), TTL: time.Hour}),
+	)
+	_, err := c.FetchBracket("http://challonge.com/xyfuz5c3")
+	var apiErr *APIError
+	assert.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusNotModified, apiErr.StatusCode)
+}
@@ -1,30 +1,53 @@
 package bracket
 
 import (
+	"context"
 	"encoding/json"
-	"io/ioutil"
 	"net/http"
 	"strconv"
 	"strings"
 	"time"
 )
 
+const defaultChallongeBaseURL = "https://api.challonge.com/v1/"
+
 type challongeAPIResponse struct {
 	Tournament *challongeTournament `json:"tournament"`
 }
 
+type challongeErrorResponse struct {
+	Errors []json.RawMessage `json:"errors"`
+}
+
+type challongeV2Error struct {
+	Title  string `json:"title"`
+	Detail string `json:"detail"`
+}
+
 type challongeTournament struct {
-	ID               int        `json:"id"`
-	Name             string     `json:"name"`
-	State            string     `json:"state"`
+	ID               int        `json:"id,omitempty"`
+	Name             string     `json:"name,omitempty"`
+	URL              string     `json:"url,omitempty"`
+	Subdomain        string     `json:"subdomain,omitempty"`
+	Description      string     `json:"description,omitempty"`
+	TournamentType   string     `json:"tournament_type,omitempty"`
+	State            string     `json:"state,omitempty"`
 	StartedAt        *time.Time `json:"started_at,omitempty"`
 	CompletedAt      *time.Time `json:"completed_at,omitempty"`
 	CreatedAt        *time.Time `json:"created_at,omitempty"`
 	UpdatedAt        *time.Time `json:"updated_at,omitempty"`
-	FullChallongeURL string     `json:"full_challonge_url"`
+	FullChallongeURL string     `json:"full_challonge_url,omitempty"`
+
+	HoldThirdPlaceMatch bool    `json:"hold_third_place_match,omitempty"`
+	GrandFinalsModifier *string `json:"grand_finals_modifier,omitempty"`
+	SwissRounds         int     `json:"swiss_rounds,omitempty"`
+	Teams               bool    `json:"teams,omitempty"`
+	// GroupStageType is the tournament_type of the group stage. Only the
+	// v2 API reports it.
+	GroupStageType string `json:"-"`
 
-	Participants []*challongeParticipantWrap `json:"participants"`
-	Matches      []*challongeMatchWrap       `json:"matches"`
+	Participants []*challongeParticipantWrap `json:"participants,omitempty"`
+	Matches      []*challongeMatchWrap       `json:"matches,omitempty"`
 }
 
 type challongeParticipantWrap struct {
@@ -32,11 +55,13 @@ type challongeParticipantWrap struct {
 }
 
 type challongeParticipant struct {
-	ID          int    `json:"id"`
-	Name        string `json:"name"`
-	Seed        int    `json:"seed"`
-	FinalRank   int    `json:"final_rank"`
-	DisplayName string `json:"display_name"`
+	ID             int    `json:"id,omitempty"`
+	Name           string `json:"name,omitempty"`
+	Seed           int    `json:"seed,omitempty"`
+	FinalRank      int    `json:"final_rank,omitempty"`
+	DisplayName    string `json:"display_name,omitempty"`
+	GroupPlayerIDs []int  `json:"group_player_ids,omitempty"`
+	Username       string `json:"challonge_username,omitempty"`
 }
 
 type challongeMatchWrap struct {
@@ -44,21 +69,40 @@ type challongeMatchWrap struct {
 }
 
 type challongeMatch struct {
-	ID                   int        `json:"id"`
-	Identifier           string     `json:"identifier"`
-	Round                int        `json:"round"`
+	ID                   int        `json:"id,omitempty"`
+	Identifier           string     `json:"identifier,omitempty"`
+	Round                int        `json:"round,omitempty"`
 	StartedAt            *time.Time `json:"started_at,omitempty"`
 	CompletedAt          *time.Time `json:"completed_at,omitempty"`
 	CreatedAt            *time.Time `json:"created_at,omitempty"`
 	UpdatedAt            *time.Time `json:"updated_at,omitempty"`
-	State                string     `json:"state"`
-	Player1ID            int        `json:"player1_id"`
-	Player2ID            int        `json:"player2_id"`
-	Player1PrereqMatchID *int       `json:"player1_prereq_match_id"`
-	Player2PrereqMatchID *int       `json:"player2_prereq_match_id"`
-	WinnerID             int        `json:"winner_id"`
-	LoserID              int        `json:"loser_id"`
-	ScoresCsv            string     `json:"scores_csv"`
+	UnderwayAt           *time.Time `json:"underway_at,omitempty"`
+	State                string     `json:"state,omitempty"`
+	Player1ID            int        `json:"player1_id,omitempty"`
+	Player2ID            int        `json:"player2_id,omitempty"`
+	Player1PrereqMatchID *int       `json:"player1_prereq_match_id,omitempty"`
+	Player2PrereqMatchID *int       `json:"player2_prereq_match_id,omitempty"`
+	WinnerID             int        `json:"winner_id,omitempty"`
+	LoserID              int        `json:"loser_id,omitempty"`
+	ScoresCsv            string     `json:"scores_csv,omitempty"`
+	Forfeited            *bool      `json:"forfeited,omitempty"`
+	GroupID              *int       `json:"group_id,omitempty"`
+}
+
+type challongeProvider struct {
+	client *Client
+}
+
+func (p *challongeProvider) Name() string {
+	return "challonge"
+}
+
+func (p *challongeProvider) MatchURL(url string) bool {
+	return isChallongeURL(url)
+}
+
+func (p *challongeProvider) FetchBracket(ctx context.Context, url string) (*Bracket, error) {
+	return fetchChallongeBracket(ctx, p.client, url)
 }
 
 func isChallongeURL(url string) bool {
@@ -80,22 +124,18 @@ func getChallongeHash(url string) string {
 	return tourneyHash
 }
 
-func getChallongeAPIURL(url string) string {
+func getChallongeAPIURL(baseURL, url string) string {
 	hash := getChallongeHash(url)
-	return "https://api.challonge.com/v1/tournaments/" + hash + ".json?include_matches=1&include_participants=1"
+	return baseURL + "tournaments/" + hash + ".json?include_matches=1&include_participants=1"
 }
 
-func fetchChallongeData(user, apiKey, apiURL string) (*challongeAPIResponse, error) {
-	req, err := http.NewRequest("GET", apiURL, nil)
-	if err != nil {
-		return nil, err
-	}
-	req.SetBasicAuth(user, apiKey)
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	body, err := ioutil.ReadAll(resp.Body)
+func fetchChallongeData(ctx context.Context, c *Client, apiURL string) (*challongeAPIResponse, error) {
+	body, err := c.get(ctx, &apiRequest{
+		Provider:     "challonge",
+		URL:          apiURL,
+		Setup:        c.setChallongeAuth,
+		DecodeErrors: decodeChallongeErrors,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -103,6 +143,37 @@ func fetchChallongeData(user, apiKey, apiURL string) (*challongeAPIResponse, err
 	return decodeChallongeData(body)
 }
 
+func (c *Client) setChallongeAuth(req *http.Request) {
+	req.SetBasicAuth(c.challongeUser, c.challongeAPIKey)
+}
+
+// decodeChallongeErrors pulls the messages out of an error payload, either
+// v1's {"errors":["..."]} or v2's JSON:API {"errors":[{"detail":"..."}]},
+// returning nil if the body isn't one.
+func decodeChallongeErrors(body []byte) []string {
+	var decoded challongeErrorResponse
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		return nil
+	}
+	var messages []string
+	for _, e := range decoded.Errors {
+		var message string
+		if err := json.Unmarshal(e, &message); err == nil {
+			messages = append(messages, message)
+			continue
+		}
+		var obj challongeV2Error
+		if err := json.Unmarshal(e, &obj); err == nil {
+			if obj.Detail != "" {
+				messages = append(messages, obj.Detail)
+			} else if obj.Title != "" {
+				messages = append(messages, obj.Title)
+			}
+		}
+	}
+	return messages
+}
+
 func decodeChallongeData(body []byte) (*challongeAPIResponse, error) {
 	var decoded challongeAPIResponse
 	err := json.Unmarshal(body, &decoded)
@@ -112,31 +183,82 @@ func decodeChallongeData(body []byte) (*challongeAPIResponse, error) {
 	return &decoded, nil
 }
 
-func convertChallongePlayers(data []*challongeParticipantWrap) []*Player {
+// convertChallongePlayers converts participants, splitting their display
+// names into prefix and gamer tag at the given separators. Annotations such
+// as "(P1W)" are left out of both.
+func convertChallongePlayers(data []*challongeParticipantWrap, separators []string) []*Player {
 	players := make([]*Player, len(data))
 	for i, d := range data {
+		prefix, gamerTag := SplitSponsor(stripAnnotations(d.Participant.DisplayName), separators)
 		players[i] = &Player{
-			ID:   strconv.Itoa(d.Participant.ID),
-			Name: d.Participant.DisplayName,
-			Seed: d.Participant.Seed,
-			Rank: d.Participant.FinalRank,
+			ID:        strconv.Itoa(d.Participant.ID),
+			Name:      d.Participant.DisplayName,
+			Seed:      d.Participant.Seed,
+			Rank:      d.Participant.FinalRank,
+			GamerTag:  gamerTag,
+			Prefix:    prefix,
+			AccountID: d.Participant.Username,
 		}
 	}
 	return players
 }
 
+// challongeTeamSeparators split a team's display name, such as
+// "Alice & Bob", into its members' names.
+var challongeTeamSeparators = []string{" & ", " + ", " / "}
+
+// addChallongeTeamMembers fills in the members of teams from their display
+// names. Challonge doesn't keep track of who is on a team, so members only
+// have a name, and an ID made from the team's ID and their place in it.
+// Players whose names don't split into at least two members are left as
+// they are.
+func addChallongeTeamMembers(players []*Player, separators []string) {
+	for _, p := range players {
+		// annotations such as "(P1W)" belong to the team, not a member,
+		// and may contain a separator themselves
+		names := []string{stripAnnotations(p.Name)}
+		for _, sep := range challongeTeamSeparators {
+			var split []string
+			for _, n := range names {
+				split = append(split, strings.Split(n, sep)...)
+			}
+			names = split
+		}
+		var members []*Player
+		for _, n := range names {
+			prefix, gamerTag := SplitSponsor(n, separators)
+			if gamerTag == "" {
+				continue
+			}
+			members = append(members, &Player{
+				ID:       p.ID + "-" + strconv.Itoa(len(members)+1),
+				Name:     strings.TrimSpace(n),
+				GamerTag: gamerTag,
+				Prefix:   prefix,
+			})
+		}
+		if len(members) < 2 {
+			continue
+		}
+		p.Members = members
+		p.GamerTag, p.Prefix = "", ""
+	}
+}
+
 func convertChallongeMatches(data []*challongeMatchWrap) []*Match {
 	matches := make([]*Match, len(data))
 	for i, d := range data {
+		player1ID := strconv.Itoa(d.Match.Player1ID)
+		player2ID := strconv.Itoa(d.Match.Player2ID)
+		// a match with unparseable scores is still returned, just
+		// without any; RawScores and ScoresError keep what went wrong
+		scores, err := ParseChallongeScores(d.Match.ScoresCsv)
+		scoresErr, _ := err.(*ScoresError)
 		p1score := 0
 		p2score := 0
-		// sum up the set results, since we're not tracking sets yet
-		for _, set := range strings.Split(d.Match.ScoresCsv, ",") {
-			scoreSplit := strings.SplitN(set, "-", 2)
-			p1setscore, _ := strconv.Atoi(scoreSplit[0])
-			p2setscore, _ := strconv.Atoi(scoreSplit[1])
-			p1score += p1setscore
-			p2score += p2setscore
+		for _, s := range scores {
+			p1score += s.Player1Score
+			p2score += s.Player2Score
 		}
 
 		var p1prereq *string
@@ -156,37 +278,102 @@ func convertChallongeMatches(data []*challongeMatchWrap) []*Match {
 			UpdatedAt:            d.Match.UpdatedAt,
 			StartedAt:            d.Match.StartedAt,
 			Round:                d.Match.Round,
-			State:                d.Match.State,
-			Player1ID:            strconv.Itoa(d.Match.Player1ID),
-			Player2ID:            strconv.Itoa(d.Match.Player2ID),
+			State:                convertChallongeMatchState(d.Match.State, d.Match.UnderwayAt != nil),
+			RawState:             d.Match.State,
+			Player1ID:            player1ID,
+			Player2ID:            player2ID,
 			Player1PrereqMatchID: p1prereq,
 			Player2PrereqMatchID: p2prereq,
 			WinnerID:             strconv.Itoa(d.Match.WinnerID),
 			LoserID:              strconv.Itoa(d.Match.LoserID),
 			Player1Score:         p1score,
 			Player2Score:         p2score,
+			Games:                convertChallongeGames(scores, player1ID, player2ID),
+			RawScores:            d.Match.ScoresCsv,
+			ScoresError:          scoresErr,
+		}
+		matches[i].Outcome = scoredOutcome(matches[i])
+		if matches[i].Outcome != OutcomeUndecided && d.Match.Forfeited != nil && *d.Match.Forfeited {
+			matches[i].Outcome = OutcomeForfeit
 		}
 	}
 	return matches
 }
 
-func convertChallongeData(data *challongeAPIResponse) *Bracket {
-	return &Bracket{
+// convertChallongeGames converts parsed scores into games, working out the
+// winner of each from the scores.
+func convertChallongeGames(scores []GameScore, player1ID, player2ID string) []Game {
+	var games []Game
+	for i, s := range scores {
+		g := Game{
+			Number:       i + 1,
+			WinnerID:     "0",
+			Player1Score: s.Player1Score,
+			Player2Score: s.Player2Score,
+		}
+		if s.Player1Score > s.Player2Score {
+			g.WinnerID = player1ID
+		} else if s.Player2Score > s.Player1Score {
+			g.WinnerID = player2ID
+		}
+		games = append(games, g)
+	}
+	return games
+}
+
+func convertChallongeFormatSettings(t *challongeTournament, b *Bracket) FormatSettings {
+	var s FormatSettings
+	switch b.Format {
+	case FormatSingleElimination:
+		s.HoldThirdPlaceMatch = t.HoldThirdPlaceMatch
+	case FormatDoubleElimination:
+		s.GrandFinalsModifier = GrandFinalsWithReset
+		if t.GrandFinalsModifier != nil {
+			switch *t.GrandFinalsModifier {
+			case "single match":
+				s.GrandFinalsModifier = GrandFinalsSingleMatch
+			case "skip":
+				s.GrandFinalsModifier = GrandFinalsSkip
+			}
+		}
+	case FormatSwiss:
+		s.SwissRounds = t.SwissRounds
+	case FormatRoundRobin:
+		s.RoundRobinIterations = roundRobinIterations(b.Matches)
+	}
+	return s
+}
+
+func convertChallongeData(data *challongeAPIResponse, separators []string) *Bracket {
+	b := &Bracket{
 		URL:       data.Tournament.FullChallongeURL,
 		Name:      data.Tournament.Name,
 		UpdatedAt: data.Tournament.UpdatedAt,
 		StartedAt: data.Tournament.StartedAt,
-		State:     data.Tournament.State,
-		Players:   convertChallongePlayers(data.Tournament.Participants),
+		State:     convertChallongeBracketState(data.Tournament.State),
+		RawState:  data.Tournament.State,
+		Players:   convertChallongePlayers(data.Tournament.Participants, separators),
 		Matches:   convertChallongeMatches(data.Tournament.Matches),
 	}
+	if data.Tournament.Teams {
+		addChallongeTeamMembers(b.Players, separators)
+	}
+	b.Format = convertChallongeFormat(data.Tournament.TournamentType)
+	b.FormatSettings = convertChallongeFormatSettings(data.Tournament, b)
+	assignSides(b.Matches, b.Format)
+	return b
 }
 
-func fetchChallongeBracket(user, apiKey, url string) (*Bracket, error) {
-	apiURL := getChallongeAPIURL(url)
-	resp, err := fetchChallongeData(user, apiKey, apiURL)
+func fetchChallongeBracket(ctx context.Context, c *Client, url string) (*Bracket, error) {
+	var resp *challongeAPIResponse
+	var err error
+	if c.challongeAPIVersion == ChallongeV2 {
+		resp, err = fetchChallongeV2Data(ctx, c, url)
+	} else {
+		resp, err = fetchChallongeData(ctx, c, getChallongeAPIURL(c.challongeBaseURL, url))
+	}
 	if err != nil {
 		return nil, err
 	}
-	return convertChallongeData(resp), err
+	return convertChallongeData(resp, c.challongeNameSeparators), err
 }
@@ -0,0 +1,61 @@
+package bracket
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ScoresError is returned by ParseChallongeScores when a score string can't
+// be parsed.
+type ScoresError struct {
+	// Input is the whole score string.
+	Input string
+	// Game is the number of the game that failed to parse, starting at 1.
+	Game int
+	// Reason describes what was wrong with the game's score.
+	Reason string
+}
+
+func (e *ScoresError) Error() string {
+	return fmt.Sprintf("bracket: invalid score %q in game %d: %s", e.Input, e.Game, e.Reason)
+}
+
+// ParseChallongeScores parses a Challonge scores_csv string, such as
+// "3-1,2-3,3-0", into the score of each game. Scores may be negative, as in
+// "0--1", which Challonge uses to mark a disqualification. An empty string
+// parses as no games.
+func ParseChallongeScores(s string) ([]GameScore, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+
+	var scores []GameScore
+	for i, game := range strings.Split(s, ",") {
+		fail := func(reason string) error {
+			return &ScoresError{Input: s, Game: i + 1, Reason: reason}
+		}
+
+		game = strings.TrimSpace(game)
+		if game == "" {
+			return nil, fail("empty score")
+		}
+		// the separator is the first dash after player 1's sign
+		sep := strings.Index(game[1:], "-")
+		if sep < 0 {
+			return nil, fail("missing \"-\" between scores")
+		}
+		sep++
+
+		p1, err := strconv.Atoi(strings.TrimSpace(game[:sep]))
+		if err != nil {
+			return nil, fail(fmt.Sprintf("player 1 score %q is not a number", strings.TrimSpace(game[:sep])))
+		}
+		p2, err := strconv.Atoi(strings.TrimSpace(game[sep+1:]))
+		if err != nil {
+			return nil, fail(fmt.Sprintf("player 2 score %q is not a number", strings.TrimSpace(game[sep+1:])))
+		}
+		scores = append(scores, GameScore{Player1Score: p1, Player2Score: p2})
+	}
+	return scores, nil
+}
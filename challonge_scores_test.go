@@ -0,0 +1,79 @@
+package bracket
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseChallongeScores(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []GameScore
+	}{
+		{"", nil},
+		{"  ", nil},
+		{"3-1", []GameScore{{3, 1}}},
+		{"3-1,2-3,3-0", []GameScore{{3, 1}, {2, 3}, {3, 0}}},
+		{"0--1", []GameScore{{0, -1}}},
+		{"-1-0", []GameScore{{-1, 0}}},
+		{"-1--1", []GameScore{{-1, -1}}},
+		{" 3 - 1 , 2-3 ", []GameScore{{3, 1}, {2, 3}}},
+		{"12-10", []GameScore{{12, 10}}},
+	}
+	for _, tt := range tests {
+		got, err := ParseChallongeScores(tt.input)
+		assert.NoError(t, err, tt.input)
+		assert.Equal(t, tt.want, got, tt.input)
+	}
+}
+
+func TestParseChallongeScoresErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		game  int
+	}{
+		{"3", 1},
+		{"3-", 1},
+		{"-3", 1},
+		{"a-1", 1},
+		{"3-1,", 2},
+		{"3-1,,2-0", 2},
+		{"3-1,2-x", 2},
+		{"3-1-1", 1},
+	}
+	for _, tt := range tests {
+		_, err := ParseChallongeScores(tt.input)
+		var scoresErr *ScoresError
+		if assert.True(t, errors.As(err, &scoresErr), tt.input) {
+			assert.Equal(t, tt.input, scoresErr.Input)
+			assert.Equal(t, tt.game, scoresErr.Game, tt.input)
+		}
+	}
+}
+
+func TestParseChallongeScoresRoundTrip(t *testing.T) {
+	scores := []GameScore{{3, 1}, {0, -1}}
+	parsed, err := ParseChallongeScores(formatChallongeGameScores(scores))
+	assert.NoError(t, err)
+	assert.Equal(t, scores, parsed)
+}
+
+func TestConvertChallongeMatchesBadScores(t *testing.T) {
+	matches := convertChallongeMatches([]*challongeMatchWrap{
+		{&challongeMatch{ID: 1, ScoresCsv: ""}},
+		{&challongeMatch{ID: 2, ScoresCsv: "garbage"}},
+	})
+	for _, m := range matches {
+		assert.Equal(t, 0, m.Player1Score)
+		assert.Equal(t, 0, m.Player2Score)
+		assert.Empty(t, m.Games)
+		assert.Equal(t, OutcomeUndecided, m.Outcome)
+	}
+	assert.Nil(t, matches[0].ScoresError)
+	assert.Equal(t, "garbage", matches[1].RawScores)
+	if assert.NotNil(t, matches[1].ScoresError) {
+		assert.Equal(t, 1, matches[1].ScoresError.Game)
+	}
+}
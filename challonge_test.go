@@ -98,11 +98,12 @@ func TestConvertChallongeData(t *testing.T) {
 	if err != nil {
 		t.Error(err)
 	}
-	bracket := convertChallongeData(resp)
+	bracket := convertChallongeData(resp, DefaultNameSeparators)
 
 	assert.Equal(t, "Missouri River Arcadian - The Sequel: Smash4 Top 16", bracket.Name)
 	assert.Equal(t, "http://HSCSmashNE.challonge.com/MRA2_s4s_t16", bracket.URL)
-	assert.Equal(t, "complete", bracket.State)
+	assert.Equal(t, BracketStateComplete, bracket.State)
+	assert.Equal(t, "complete", bracket.RawState)
 	updatedAt, _ := time.Parse(time.RFC3339, "2016-04-03T00:00:43.621-06:00")
 	assert.Equal(t, &updatedAt, bracket.UpdatedAt)
 	startedAt, _ := time.Parse(time.RFC3339, "2016-04-02T21:02:39.766-06:00")
@@ -112,6 +113,8 @@ func TestConvertChallongeData(t *testing.T) {
 	player := players[0]
 	assert.Equal(t, "38172466", player.ID)
 	assert.Equal(t, "(P1W) DPS|Dr. Pizza", player.Name)
+	assert.Equal(t, "Dr. Pizza", player.GamerTag)
+	assert.Equal(t, "DPS", player.Prefix)
 	assert.Equal(t, 9, player.Rank)
 	assert.Equal(t, 1, player.Seed)
 	player = players[1]
@@ -129,11 +132,52 @@ func TestConvertChallongeData(t *testing.T) {
 	assert.Equal(t, 1, match.Round)
 	assert.Equal(t, &updatedAt, match.UpdatedAt)
 	assert.Equal(t, &startedAt, match.StartedAt)
-	assert.Equal(t, "complete", match.State)
+	assert.Equal(t, MatchStateComplete, match.State)
 	assert.Equal(t, "38172466", match.Player1ID)
 	assert.Equal(t, 0, match.Player1Score)
 	assert.Equal(t, "38172533", match.Player2ID)
 	assert.Equal(t, -1, match.Player2Score)
 	assert.Equal(t, "38172466", match.WinnerID)
 	assert.Equal(t, "38172533", match.LoserID)
+	assert.Equal(t, []Game{{Number: 1, WinnerID: "38172466", Player1Score: 0, Player2Score: -1}}, match.Games)
+	assert.Equal(t, OutcomeDQ, match.Outcome)
+}
+
+func TestConvertChallongeGames(t *testing.T) {
+	games := convertChallongeGames([]GameScore{{3, 1}, {2, 3}, {1, 1}}, "1", "2")
+	assert.Equal(t, []Game{
+		{Number: 1, WinnerID: "1", Player1Score: 3, Player2Score: 1},
+		{Number: 2, WinnerID: "2", Player1Score: 2, Player2Score: 3},
+		{Number: 3, WinnerID: "0", Player1Score: 1, Player2Score: 1},
+	}, games)
+	assert.Empty(t, convertChallongeGames(nil, "1", "2"))
+}
+
+func TestConvertChallongeTeams(t *testing.T) {
+	resp := &challongeAPIResponse{&challongeTournament{
+		Teams:          true,
+		TournamentType: "single elimination",
+		Participants: []*challongeParticipantWrap{
+			{&challongeParticipant{ID: 10, DisplayName: "DPS|Dr. Pizza & Hite", Seed: 1}},
+			{&challongeParticipant{ID: 11, DisplayName: "Solo", Seed: 2}},
+			{&challongeParticipant{ID: 12, DisplayName: "(P1W) Alice & Bob", Seed: 3}},
+			{&challongeParticipant{ID: 13, DisplayName: "[Alice & Bob] Carol", Seed: 4}},
+		},
+	}}
+
+	players := convertChallongeData(resp, DefaultNameSeparators).Players
+	assert.Equal(t, "", players[0].GamerTag)
+	assert.Equal(t, []*Player{
+		{ID: "10-1", Name: "DPS|Dr. Pizza", GamerTag: "Dr. Pizza", Prefix: "DPS"},
+		{ID: "10-2", Name: "Hite", GamerTag: "Hite"},
+	}, players[0].Members)
+	// a name that doesn't split is left alone
+	assert.Empty(t, players[1].Members)
+	assert.Equal(t, "Solo", players[1].GamerTag)
+	// annotations aren't part of any member's name, or split on
+	assert.Equal(t, []*Player{
+		{ID: "12-1", Name: "Alice", GamerTag: "Alice"},
+		{ID: "12-2", Name: "Bob", GamerTag: "Bob"},
+	}, players[2].Members)
+	assert.Empty(t, players[3].Members)
 }
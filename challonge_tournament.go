@@ -0,0 +1,205 @@
+package bracket
+
+import (
+	"context"
+	"sort"
+	"strconv"
+)
+
+// FetchTournament implements TournamentFetcher. A Challonge tournament has
+// a single event, with a group stage phase followed by a final stage if
+// group stages are enabled, and a single phase otherwise.
+func (p *challongeProvider) FetchTournament(ctx context.Context, url string) (*Tournament, error) {
+	resp, err := fetchChallongeTournamentData(ctx, p.client, url)
+	if err != nil {
+		return nil, err
+	}
+	return convertChallongeTournament(resp, p.client.challongeNameSeparators), nil
+}
+
+// FetchTournamentEvent implements TournamentFetcher.
+func (p *challongeProvider) FetchTournamentEvent(ctx context.Context, url string) (*TournamentEvent, error) {
+	resp, err := fetchChallongeTournamentData(ctx, p.client, url)
+	if err != nil {
+		return nil, err
+	}
+	return convertChallongeTournament(resp, p.client.challongeNameSeparators).Events[0], nil
+}
+
+func fetchChallongeTournamentData(ctx context.Context, c *Client, url string) (*challongeAPIResponse, error) {
+	if c.challongeAPIVersion == ChallongeV2 {
+		return fetchChallongeV2Data(ctx, c, url)
+	}
+	return fetchChallongeData(ctx, c, getChallongeAPIURL(c.challongeBaseURL, url))
+}
+
+func convertChallongeTournament(data *challongeAPIResponse, separators []string) *Tournament {
+	t := data.Tournament
+	id := strconv.Itoa(t.ID)
+	event := &TournamentEvent{ID: id, Name: t.Name, URL: t.FullChallongeURL}
+
+	var groupIDs []int
+	seen := make(map[int]bool)
+	for _, m := range t.Matches {
+		if m.Match.GroupID != nil && !seen[*m.Match.GroupID] {
+			seen[*m.Match.GroupID] = true
+			groupIDs = append(groupIDs, *m.Match.GroupID)
+		}
+	}
+
+	if len(groupIDs) == 0 {
+		event.Phases = []*Phase{{
+			ID:     id,
+			Name:   t.Name,
+			Order:  1,
+			Groups: []*PhaseGroup{{ID: id, URL: t.FullChallongeURL, Bracket: convertChallongeData(data, separators)}},
+		}}
+	} else {
+		event.Phases = convertChallongeStages(data, groupIDs, separators)
+	}
+
+	return &Tournament{
+		ID:      id,
+		Name:    t.Name,
+		URL:     t.FullChallongeURL,
+		StartAt: t.StartedAt,
+		EndAt:   t.CompletedAt,
+		Events:  []*TournamentEvent{event},
+	}
+}
+
+// convertChallongeStages splits a tournament with group stages into a
+// group stage phase and a final stage phase. Players in group stage matches
+// have separate IDs, which are mapped back to their participant IDs so the
+// same player has the same ID in both phases.
+func convertChallongeStages(data *challongeAPIResponse, groupIDs []int, separators []string) []*Phase {
+	t := data.Tournament
+	sort.Ints(groupIDs)
+
+	owners := make(map[string]string)
+	for _, p := range t.Participants {
+		for _, groupPlayerID := range p.Participant.GroupPlayerIDs {
+			owners[strconv.Itoa(groupPlayerID)] = strconv.Itoa(p.Participant.ID)
+		}
+	}
+	owner := func(id string) string {
+		if o, ok := owners[id]; ok {
+			return o
+		}
+		return id
+	}
+
+	players := convertChallongePlayers(t.Participants, separators)
+	if t.Teams {
+		addChallongeTeamMembers(players, separators)
+	}
+	matches := convertChallongeMatches(t.Matches)
+	groupMatches := make(map[int][]*Match)
+	var finalMatches []*Match
+	for i, m := range matches {
+		groupID := t.Matches[i].Match.GroupID
+		if groupID == nil {
+			finalMatches = append(finalMatches, m)
+			continue
+		}
+		m.Player1ID = owner(m.Player1ID)
+		m.Player2ID = owner(m.Player2ID)
+		m.WinnerID = owner(m.WinnerID)
+		m.LoserID = owner(m.LoserID)
+		for j := range m.Games {
+			m.Games[j].WinnerID = owner(m.Games[j].WinnerID)
+		}
+		groupMatches[*groupID] = append(groupMatches[*groupID], m)
+	}
+
+	groupFormat := convertChallongeFormat(t.GroupStageType)
+	if groupFormat == FormatUnknown {
+		groupFormat = challongeGroupFormat(groupMatches)
+	}
+	// Challonge has no ID for the group stage, so it's named after the
+	// tournament, whose ID the final stage uses
+	groupPhase := &Phase{ID: strconv.Itoa(t.ID) + "-groups", Name: "Group Stage", Order: 1}
+	for i, groupID := range groupIDs {
+		// Challonge names groups by letter in the order they were created
+		identifier := string(rune('A' + i%26))
+		b := stageBracket(t.FullChallongeURL, t.Name+" – Group "+identifier, players, groupMatches[groupID], groupFormat)
+		// group stage ranks aren't reported, only final ones
+		for _, p := range b.Players {
+			p.Rank = 0
+		}
+		groupPhase.Groups = append(groupPhase.Groups, &PhaseGroup{
+			ID:         strconv.Itoa(groupID),
+			Identifier: identifier,
+			URL:        t.FullChallongeURL,
+			Bracket:    b,
+		})
+	}
+
+	format := convertChallongeFormat(t.TournamentType)
+	final := stageBracket(t.FullChallongeURL, t.Name+" – Final Stage", players, finalMatches, format)
+	final.FormatSettings = convertChallongeFormatSettings(t, final)
+	finalPhase := &Phase{
+		ID:     strconv.Itoa(t.ID),
+		Name:   "Final Stage",
+		Order:  2,
+		Groups: []*PhaseGroup{{ID: strconv.Itoa(t.ID), URL: t.FullChallongeURL, Bracket: final}},
+	}
+	return []*Phase{groupPhase, finalPhase}
+}
+
+// challongeGroupFormat works out the format of a group stage from its
+// matches, for the v1 API which doesn't report it. Elimination groups link
+// matches through their prerequisites, and double elimination ones have
+// losers rounds. Swiss groups can't be told apart from round robin ones.
+func challongeGroupFormat(groupMatches map[int][]*Match) BracketFormat {
+	format := FormatRoundRobin
+	for _, matches := range groupMatches {
+		for _, m := range matches {
+			if m.Round < 0 {
+				return FormatDoubleElimination
+			}
+			if m.Player1PrereqMatchID != nil || m.Player2PrereqMatchID != nil {
+				format = FormatSingleElimination
+			}
+		}
+	}
+	return format
+}
+
+// stageBracket builds the bracket for one stage of a Challonge tournament
+// out of its matches and the players in them.
+func stageBracket(url, name string, players []*Player, matches []*Match, format BracketFormat) *Bracket {
+	b := &Bracket{URL: url, Name: name, Matches: matches, Format: format}
+	inStage := make(map[string]bool)
+	for _, m := range matches {
+		inStage[m.Player1ID] = true
+		inStage[m.Player2ID] = true
+	}
+	for _, p := range players {
+		if inStage[p.ID] {
+			player := *p
+			b.Players = append(b.Players, &player)
+		}
+	}
+
+	var states []BracketState
+	for _, m := range matches {
+		switch m.State {
+		case MatchStateComplete:
+			states = append(states, BracketStateComplete)
+		case MatchStateOpen, MatchStateInProgress:
+			states = append(states, BracketStateInProgress)
+		default:
+			states = append(states, BracketStatePending)
+		}
+		if m.StartedAt != nil && (b.StartedAt == nil || m.StartedAt.Before(*b.StartedAt)) {
+			b.StartedAt = m.StartedAt
+		}
+		if m.UpdatedAt != nil && (b.UpdatedAt == nil || m.UpdatedAt.After(*b.UpdatedAt)) {
+			b.UpdatedAt = m.UpdatedAt
+		}
+	}
+	b.State = combinedState(states)
+	assignSides(b.Matches, format)
+	return b
+}
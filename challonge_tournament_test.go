@@ -0,0 +1,93 @@
+package bracket
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvertChallongeTournament(t *testing.T) {
+	b, err := ioutil.ReadFile("testdata/challonge.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := decodeChallongeData(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tournament := convertChallongeTournament(resp, DefaultNameSeparators)
+	assert.Equal(t, "2385234", tournament.ID)
+	assert.Equal(t, "http://HSCSmashNE.challonge.com/MRA2_s4s_t16", tournament.URL)
+	assert.Len(t, tournament.Events, 1)
+	assert.Len(t, tournament.Events[0].Phases, 1)
+	assert.Equal(t, convertChallongeData(resp, DefaultNameSeparators), tournament.Events[0].Phases[0].Groups[0].Bracket)
+}
+
+func TestConvertChallongeGroupStages(t *testing.T) {
+	var resp challongeAPIResponse
+	err := json.Unmarshal([]byte(`{"tournament": {
+		"id": 1,
+		"name": "Weekly",
+		"tournament_type": "single elimination",
+		"full_challonge_url": "http://challonge.com/weekly1",
+		"participants": [
+			{"participant": {"id": 10, "display_name": "Alice", "seed": 1, "final_rank": 1, "group_player_ids": [110]}},
+			{"participant": {"id": 11, "display_name": "Bob", "seed": 2, "final_rank": 2, "group_player_ids": [111]}},
+			{"participant": {"id": 12, "display_name": "Carol", "seed": 3, "final_rank": 3, "group_player_ids": [112]}},
+			{"participant": {"id": 13, "display_name": "Dan", "seed": 4, "final_rank": 3, "group_player_ids": [113]}}
+		],
+		"matches": [
+			{"match": {"id": 1, "group_id": 500, "round": 1, "state": "complete", "player1_id": 110, "player2_id": 113, "winner_id": 110, "loser_id": 113, "scores_csv": "2-0"}},
+			{"match": {"id": 2, "group_id": 501, "round": 1, "state": "complete", "player1_id": 111, "player2_id": 112, "winner_id": 111, "loser_id": 112, "scores_csv": "2-1"}},
+			{"match": {"id": 3, "round": 1, "state": "complete", "player1_id": 10, "player2_id": 11, "winner_id": 10, "loser_id": 11, "scores_csv": "3-2"}}
+		]
+	}}`), &resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	event := convertChallongeTournament(&resp, DefaultNameSeparators).Events[0]
+	assert.Len(t, event.Phases, 2)
+	assert.Equal(t, "1-groups", event.Phases[0].ID)
+	assert.Equal(t, "1", event.Phases[1].ID)
+	groups := event.Phases[0].Groups
+	assert.Len(t, groups, 2)
+	assert.Equal(t, "A", groups[0].Identifier)
+	assert.Equal(t, "Weekly – Group B", groups[1].Bracket.Name)
+	assert.Equal(t, MatchSideRoundRobin, groups[0].Bracket.Matches[0].Side)
+
+	// group player IDs are mapped back to participant IDs
+	match := groups[0].Bracket.Matches[0]
+	assert.Equal(t, "10", match.Player1ID)
+	assert.Equal(t, "13", match.Player2ID)
+	assert.Equal(t, "10", match.WinnerID)
+	assert.Equal(t, "10", match.Games[0].WinnerID)
+	assert.Equal(t, []*Player{{ID: "10", Name: "Alice", Seed: 1, GamerTag: "Alice"}, {ID: "13", Name: "Dan", Seed: 4, GamerTag: "Dan"}}, groups[0].Bracket.Players)
+
+	final := event.Phases[1].Groups[0].Bracket
+	assert.Equal(t, FormatSingleElimination, final.Format)
+	assert.Equal(t, BracketStateComplete, final.State)
+	assert.Len(t, final.Players, 2)
+	assert.Equal(t, "Final", final.Matches[0].RoundLabel)
+
+	b := event.Bracket()
+	assert.Len(t, b.Players, 4)
+	assert.Len(t, b.Matches, 3)
+	assert.Equal(t, 1, b.Players[0].Rank)
+}
+
+func TestChallongeGroupFormat(t *testing.T) {
+	prereq := "1"
+	assert.Equal(t, FormatRoundRobin, challongeGroupFormat(map[int][]*Match{
+		1: {{ID: "1", Round: 1}, {ID: "2", Round: 2}},
+	}))
+	assert.Equal(t, FormatSingleElimination, challongeGroupFormat(map[int][]*Match{
+		1: {{ID: "1", Round: 1}, {ID: "2", Round: 2, Player1PrereqMatchID: &prereq}},
+	}))
+	assert.Equal(t, FormatDoubleElimination, challongeGroupFormat(map[int][]*Match{
+		1: {{ID: "1", Round: 1}},
+		2: {{ID: "2", Round: -1, Player1PrereqMatchID: &prereq}},
+	}))
+}
@@ -0,0 +1,491 @@
+package bracket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ChallongeAPIVersion selects which version of the Challonge API a
+// Client uses. Both versions produce identical brackets.
+type ChallongeAPIVersion int
+
+const (
+	// ChallongeV1 is the original Challonge API, authenticated with a
+	// username and API key.
+	ChallongeV1 ChallongeAPIVersion = iota + 1
+	// ChallongeV2 is the JSON:API based Challonge API, authenticated with
+	// OAuth2 or an API key.
+	ChallongeV2
+)
+
+const (
+	defaultChallongeV2BaseURL  = "https://api.challonge.com/v2.1/"
+	defaultChallongeTokenURL   = "https://api.challonge.com/oauth/token"
+	challongeJSONAPIType       = "application/vnd.api+json"
+	challongeTokenExpiryMargin = time.Minute
+)
+
+// WithChallongeAPIVersion selects the version of the Challonge API to use.
+// The default is ChallongeV1.
+func WithChallongeAPIVersion(version ChallongeAPIVersion) Option {
+	return func(c *Client) {
+		c.challongeAPIVersion = version
+	}
+}
+
+// WithChallongeClientCredentials authenticates with Challonge API v2 using
+// the OAuth2 client credentials grant. Access tokens are requested as
+// needed and reused until they expire.
+func WithChallongeClientCredentials(clientID, clientSecret string, scopes ...string) Option {
+	return func(c *Client) {
+		c.challongeTokens = &challongeTokenSource{
+			clientID:     clientID,
+			clientSecret: clientSecret,
+			scopes:       scopes,
+		}
+	}
+}
+
+// WithChallongeAccessToken authenticates with Challonge API v2 using an
+// OAuth2 access token obtained on behalf of a user.
+func WithChallongeAccessToken(token string) Option {
+	return func(c *Client) {
+		c.challongeTokens = &challongeTokenSource{token: token}
+	}
+}
+
+// WithChallongeTokenURL overrides the URL OAuth2 access tokens are
+// requested from.
+func WithChallongeTokenURL(tokenURL string) Option {
+	return func(c *Client) {
+		c.challongeTokenURL = tokenURL
+	}
+}
+
+// challongeTokenSource hands out OAuth2 access tokens, requesting a new
+// one with the client credentials grant once the current one expires.
+// A source without client credentials always returns its fixed token.
+type challongeTokenSource struct {
+	clientID     string
+	clientSecret string
+	scopes       []string
+
+	mu     sync.Mutex
+	token  string
+	expiry time.Time
+	// refreshing is closed once an in-flight token request finishes.
+	refreshing chan struct{}
+}
+
+type challongeTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+// accessToken returns a current access token. Only one token request is
+// made at a time, and callers wait for it without holding the lock, so a
+// slow token endpoint doesn't hold up requests that already have a token.
+func (s *challongeTokenSource) accessToken(ctx context.Context, c *Client) (string, error) {
+	for {
+		s.mu.Lock()
+		if s.clientID == "" || (s.token != "" && (s.expiry.IsZero() || time.Now().Before(s.expiry))) {
+			token := s.token
+			s.mu.Unlock()
+			return token, nil
+		}
+		if wait := s.refreshing; wait != nil {
+			s.mu.Unlock()
+			select {
+			case <-wait:
+				// try again, requesting a token ourselves if that one failed
+				continue
+			case <-ctx.Done():
+				return "", ctx.Err()
+			}
+		}
+		done := make(chan struct{})
+		s.refreshing = done
+		s.mu.Unlock()
+
+		token, expiry, err := s.requestToken(ctx, c)
+
+		s.mu.Lock()
+		if err == nil {
+			s.token, s.expiry = token, expiry
+		}
+		s.refreshing = nil
+		s.mu.Unlock()
+		close(done)
+		return token, err
+	}
+}
+
+// requestToken requests a new access token with the client credentials
+// grant, returning it with its expiry, or a zero time if it doesn't expire.
+func (s *challongeTokenSource) requestToken(ctx context.Context, c *Client) (string, time.Time, error) {
+	form := url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {s.clientID},
+		"client_secret": {s.clientSecret},
+	}
+	if len(s.scopes) > 0 {
+		form.Set("scope", strings.Join(s.scopes, " "))
+	}
+	resp, err := c.do(ctx, &apiRequest{
+		Provider: "challonge",
+		Method:   "POST",
+		URL:      c.challongeTokenURL,
+		Body:     []byte(form.Encode()),
+		Setup: func(req *http.Request) {
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		},
+		DecodeErrors: decodeChallongeErrors,
+	})
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	var decoded challongeTokenResponse
+	if err := json.Unmarshal(resp.Body, &decoded); err != nil {
+		return "", time.Time{}, err
+	}
+
+	var expiry time.Time
+	if decoded.ExpiresIn > 0 {
+		expiry = time.Now().Add(time.Duration(decoded.ExpiresIn)*time.Second - challongeTokenExpiryMargin)
+	}
+	return decoded.AccessToken, expiry, nil
+}
+
+// challongeV2Setup returns a function that adds the v2 headers to a
+// request. OAuth2 is used when configured; otherwise the v1 API key is
+// sent, which v2 also accepts.
+func challongeV2Setup(ctx context.Context, c *Client) (func(*http.Request), error) {
+	authType, auth := "v1", c.challongeAPIKey
+	if c.challongeTokens != nil {
+		token, err := c.challongeTokens.accessToken(ctx, c)
+		if err != nil {
+			return nil, err
+		}
+		authType, auth = "v2", "Bearer "+token
+	}
+	return func(req *http.Request) {
+		req.Header.Set("Content-Type", challongeJSONAPIType)
+		req.Header.Set("Accept", "application/json")
+		req.Header.Set("Authorization-Type", authType)
+		req.Header.Set("Authorization", auth)
+	}, nil
+}
+
+// challongeV2Document is a JSON:API document. Data holds either a single
+// resource or an array of them.
+type challongeV2Document struct {
+	Data     json.RawMessage        `json:"data"`
+	Included []*challongeV2Resource `json:"included"`
+	Links    *challongeV2Links      `json:"links"`
+}
+
+type challongeV2Links struct {
+	Next *string `json:"next"`
+}
+
+type challongeV2Resource struct {
+	ID            string                              `json:"id"`
+	Type          string                              `json:"type"`
+	Attributes    json.RawMessage                     `json:"attributes"`
+	Relationships map[string]*challongeV2Relationship `json:"relationships"`
+}
+
+// challongeV2Relationship links to one resource or, for to-many
+// relationships, an array of them.
+type challongeV2Relationship struct {
+	Data json.RawMessage `json:"data"`
+}
+
+type challongeV2ResourceID struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+}
+
+type challongeV2Timestamps struct {
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	CreatedAt   *time.Time `json:"created_at,omitempty"`
+	UpdatedAt   *time.Time `json:"updated_at,omitempty"`
+	UnderwayAt  *time.Time `json:"underway_at,omitempty"`
+}
+
+type challongeV2Tournament struct {
+	Name              string                        `json:"name"`
+	TournamentType    string                        `json:"tournament_type"`
+	MatchOptions      *challongeV2MatchOptions      `json:"match_options"`
+	DoubleElimOptions *challongeV2DoubleElimOptions `json:"double_elimination_options"`
+	SwissOptions      *challongeV2SwissOptions      `json:"swiss_options"`
+	GroupStageOptions *challongeV2GroupStageOptions `json:"group_stage_options"`
+	State             string                        `json:"state"`
+	FullChallongeURL  string                        `json:"full_challonge_url"`
+	Timestamps        challongeV2Timestamps         `json:"timestamps"`
+}
+
+// challongeV2MatchOptions replaces v1's hold_third_place_match with the
+// lowest rank decided by a consolation match, which is 3 when there's a
+// third place match.
+type challongeV2MatchOptions struct {
+	ConsolationMatchesTargetRank *int `json:"consolation_matches_target_rank"`
+}
+
+type challongeV2DoubleElimOptions struct {
+	GrandFinalsModifier *string `json:"grand_finals_modifier"`
+}
+
+type challongeV2SwissOptions struct {
+	Rounds int `json:"rounds"`
+}
+
+type challongeV2GroupStageOptions struct {
+	StageType string `json:"stage_type"`
+}
+
+type challongeV2Participant struct {
+	Name           string `json:"name"`
+	DisplayName    string `json:"display_name"`
+	Seed           int    `json:"seed"`
+	FinalRank      int    `json:"final_rank"`
+	Username       string `json:"username"`
+	GroupPlayerIDs []int  `json:"group_player_ids"`
+}
+
+type challongeV2Match struct {
+	Identifier           string                `json:"identifier"`
+	Round                int                   `json:"round"`
+	State                string                `json:"state"`
+	ScoreInSets          [][]int               `json:"score_in_sets"`
+	WinnerID             *int                  `json:"winner_id"`
+	LoserID              *int                  `json:"loser_id"`
+	Player1PrereqMatchID *int                  `json:"player1_prereq_match_id"`
+	Player2PrereqMatchID *int                  `json:"player2_prereq_match_id"`
+	GroupID              *int                  `json:"group_id"`
+	Timestamps           challongeV2Timestamps `json:"timestamps"`
+}
+
+// resources returns every resource in the document, from both data
+// and included.
+func (d *challongeV2Document) resources() ([]*challongeV2Resource, error) {
+	var resources []*challongeV2Resource
+	data := strings.TrimSpace(string(d.Data))
+	switch {
+	case strings.HasPrefix(data, "["):
+		if err := json.Unmarshal(d.Data, &resources); err != nil {
+			return nil, err
+		}
+	case strings.HasPrefix(data, "{"):
+		var r challongeV2Resource
+		if err := json.Unmarshal(d.Data, &r); err != nil {
+			return nil, err
+		}
+		resources = append(resources, &r)
+	}
+	return append(resources, d.Included...), nil
+}
+
+// relatedID returns the ID of the resource in a to-one relationship, or 0
+// if there isn't one.
+func (r *challongeV2Resource) relatedID(name string) int {
+	rel := r.Relationships[name]
+	if rel == nil {
+		return 0
+	}
+	var related challongeV2ResourceID
+	if err := json.Unmarshal(rel.Data, &related); err != nil {
+		return 0
+	}
+	id, _ := strconv.Atoi(related.ID)
+	return id
+}
+
+func getChallongeV2APIURLs(baseURL, url string) []string {
+	tournament := baseURL + "tournaments/" + getChallongeHash(url)
+	return []string{
+		tournament + ".json",
+		tournament + "/participants.json",
+		tournament + "/matches.json",
+	}
+}
+
+// decodeChallongeV2Data converts JSON:API documents into the v1 response
+// shape, so that both API versions share the same conversion to a Bracket.
+// Resources may appear in any of the documents, either as primary data or
+// as included resources.
+func decodeChallongeV2Data(bodies ...[]byte) (*challongeAPIResponse, error) {
+	t := &challongeTournament{}
+	seen := make(map[string]bool)
+	for _, body := range bodies {
+		var doc challongeV2Document
+		if err := json.Unmarshal(body, &doc); err != nil {
+			return nil, err
+		}
+		resources, err := doc.resources()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, r := range resources {
+			key := r.Type + "/" + r.ID
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			id, _ := strconv.Atoi(r.ID)
+
+			switch r.Type {
+			case "tournament":
+				var attrs challongeV2Tournament
+				if err := json.Unmarshal(r.Attributes, &attrs); err != nil {
+					return nil, err
+				}
+				t.ID = id
+				t.Name = attrs.Name
+				t.TournamentType = attrs.TournamentType
+				if attrs.MatchOptions != nil && attrs.MatchOptions.ConsolationMatchesTargetRank != nil {
+					t.HoldThirdPlaceMatch = *attrs.MatchOptions.ConsolationMatchesTargetRank >= 3
+				}
+				if attrs.DoubleElimOptions != nil {
+					t.GrandFinalsModifier = attrs.DoubleElimOptions.GrandFinalsModifier
+				}
+				if attrs.SwissOptions != nil {
+					t.SwissRounds = attrs.SwissOptions.Rounds
+				}
+				if attrs.GroupStageOptions != nil {
+					t.GroupStageType = attrs.GroupStageOptions.StageType
+				}
+				t.State = attrs.State
+				t.FullChallongeURL = attrs.FullChallongeURL
+				t.StartedAt = attrs.Timestamps.StartedAt
+				t.CompletedAt = attrs.Timestamps.CompletedAt
+				t.CreatedAt = attrs.Timestamps.CreatedAt
+				t.UpdatedAt = attrs.Timestamps.UpdatedAt
+
+			case "participant":
+				var attrs challongeV2Participant
+				if err := json.Unmarshal(r.Attributes, &attrs); err != nil {
+					return nil, err
+				}
+				displayName := attrs.DisplayName
+				if displayName == "" {
+					displayName = attrs.Name
+				}
+				t.Participants = append(t.Participants, &challongeParticipantWrap{&challongeParticipant{
+					ID:             id,
+					Name:           attrs.Name,
+					Seed:           attrs.Seed,
+					FinalRank:      attrs.FinalRank,
+					DisplayName:    displayName,
+					GroupPlayerIDs: attrs.GroupPlayerIDs,
+					Username:       attrs.Username,
+				}})
+
+			case "match":
+				var attrs challongeV2Match
+				if err := json.Unmarshal(r.Attributes, &attrs); err != nil {
+					return nil, err
+				}
+				m := &challongeMatch{
+					ID:                   id,
+					Identifier:           attrs.Identifier,
+					Round:                attrs.Round,
+					State:                attrs.State,
+					Player1ID:            r.relatedID("player1"),
+					Player2ID:            r.relatedID("player2"),
+					Player1PrereqMatchID: attrs.Player1PrereqMatchID,
+					Player2PrereqMatchID: attrs.Player2PrereqMatchID,
+					GroupID:              attrs.GroupID,
+					ScoresCsv:            formatChallongeScores(attrs.ScoreInSets),
+					StartedAt:            attrs.Timestamps.StartedAt,
+					CompletedAt:          attrs.Timestamps.CompletedAt,
+					CreatedAt:            attrs.Timestamps.CreatedAt,
+					UpdatedAt:            attrs.Timestamps.UpdatedAt,
+					UnderwayAt:           attrs.Timestamps.UnderwayAt,
+				}
+				if attrs.WinnerID != nil {
+					m.WinnerID = *attrs.WinnerID
+				}
+				if attrs.LoserID != nil {
+					m.LoserID = *attrs.LoserID
+				}
+				t.Matches = append(t.Matches, &challongeMatchWrap{m})
+			}
+		}
+	}
+	return &challongeAPIResponse{Tournament: t}, nil
+}
+
+// formatChallongeScores renders per-set scores in the v1 scores_csv
+// format, e.g. [[3,1],[0,-1]] becomes "3-1,0--1".
+func formatChallongeScores(sets [][]int) string {
+	formatted := make([]string, 0, len(sets))
+	for _, set := range sets {
+		if len(set) != 2 {
+			continue
+		}
+		formatted = append(formatted, fmt.Sprintf("%d-%d", set[0], set[1]))
+	}
+	return strings.Join(formatted, ",")
+}
+
+// sameOrigin reports whether two URLs have the same scheme and host.
+func sameOrigin(a, b string) bool {
+	ua, err := url.Parse(a)
+	if err != nil {
+		return false
+	}
+	ub, err := url.Parse(b)
+	if err != nil {
+		return false
+	}
+	return strings.EqualFold(ua.Scheme, ub.Scheme) && strings.EqualFold(ua.Host, ub.Host)
+}
+
+// fetchChallongeV2Data fetches the tournament, its participants and its
+// matches, following pagination links until every page has been fetched.
+func fetchChallongeV2Data(ctx context.Context, c *Client, url string) (*challongeAPIResponse, error) {
+	var bodies [][]byte
+	for _, apiURL := range getChallongeV2APIURLs(c.challongeBaseURL, url) {
+		for apiURL != "" {
+			setup, err := challongeV2Setup(ctx, c)
+			if err != nil {
+				return nil, err
+			}
+			body, err := c.get(ctx, &apiRequest{
+				Provider:     "challonge",
+				URL:          apiURL,
+				Setup:        setup,
+				DecodeErrors: decodeChallongeErrors,
+			})
+			if err != nil {
+				return nil, err
+			}
+			bodies = append(bodies, body)
+
+			var doc challongeV2Document
+			if err := json.Unmarshal(body, &doc); err != nil {
+				return nil, err
+			}
+			apiURL = ""
+			if doc.Links != nil && doc.Links.Next != nil {
+				apiURL = *doc.Links.Next
+				// the link is followed with our credentials, so it
+				// mustn't lead anywhere but the API
+				if !sameOrigin(apiURL, c.challongeBaseURL) {
+					return nil, fmt.Errorf("bracket: challonge pagination link %q is not on %s", apiURL, c.challongeBaseURL)
+				}
+			}
+		}
+	}
+	return decodeChallongeV2Data(bodies...)
+}
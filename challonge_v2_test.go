@@ -0,0 +1,277 @@
+package bracket
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func readChallongeV2Fixtures(t *testing.T) map[string][]byte {
+	fixtures := make(map[string][]byte)
+	for path, name := range map[string]string{
+		"/v2.1/tournaments/HSCSmashNE-MRA2_s4s_t16.json":              "challonge_v2_tournament.json",
+		"/v2.1/tournaments/HSCSmashNE-MRA2_s4s_t16/participants.json": "challonge_v2_participants.json",
+		"/v2.1/tournaments/HSCSmashNE-MRA2_s4s_t16/matches.json":      "challonge_v2_matches.json",
+	} {
+		b, err := ioutil.ReadFile("testdata/" + name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fixtures[path] = b
+	}
+	return fixtures
+}
+
+func TestDecodeChallongeV2MatchesV1(t *testing.T) {
+	fixtures := readChallongeV2Fixtures(t)
+	v2, err := decodeChallongeV2Data(
+		fixtures["/v2.1/tournaments/HSCSmashNE-MRA2_s4s_t16.json"],
+		fixtures["/v2.1/tournaments/HSCSmashNE-MRA2_s4s_t16/participants.json"],
+		fixtures["/v2.1/tournaments/HSCSmashNE-MRA2_s4s_t16/matches.json"],
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := ioutil.ReadFile("testdata/challonge.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v1, err := decodeChallongeData(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, convertChallongeData(v1, DefaultNameSeparators), convertChallongeData(v2, DefaultNameSeparators))
+}
+
+func TestDecodeChallongeV2FormatMatchesV1(t *testing.T) {
+	tests := []struct {
+		v1, v2 string
+	}{
+		{
+			`"tournament_type":"single elimination","hold_third_place_match":true`,
+			`"tournament_type":"single elimination","match_options":{"consolation_matches_target_rank":3}`,
+		},
+		{
+			`"tournament_type":"single elimination","hold_third_place_match":false`,
+			`"tournament_type":"single elimination","match_options":{"consolation_matches_target_rank":null}`,
+		},
+		{
+			`"tournament_type":"double elimination","grand_finals_modifier":"single match"`,
+			`"tournament_type":"double elimination","double_elimination_options":{"grand_finals_modifier":"single match"}`,
+		},
+		{
+			`"tournament_type":"swiss","swiss_rounds":5`,
+			`"tournament_type":"swiss","swiss_options":{"rounds":5}`,
+		},
+	}
+	for _, tt := range tests {
+		v1, err := decodeChallongeData([]byte(`{"tournament":{` + tt.v1 + `}}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		v2, err := decodeChallongeV2Data([]byte(`{"data":{"id":"1","type":"tournament","attributes":{` + tt.v2 + `}}}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := convertChallongeData(v1, DefaultNameSeparators)
+		got := convertChallongeData(v2, DefaultNameSeparators)
+		assert.Equal(t, want.Format, got.Format, tt.v2)
+		assert.Equal(t, want.FormatSettings, got.FormatSettings, tt.v2)
+	}
+}
+
+func TestDecodeChallongeV2GroupStages(t *testing.T) {
+	resp, err := decodeChallongeV2Data(
+		[]byte(`{"data":{"id":"1","type":"tournament","attributes":{"name":"Weekly","tournament_type":"single elimination",
+			"group_stage_enabled":true,"group_stage_options":{"stage_type":"double elimination"}}}}`),
+		[]byte(`{"data":[
+			{"id":"10","type":"participant","attributes":{"name":"Alice","seed":1,"group_id":500,"group_player_ids":[110]}},
+			{"id":"11","type":"participant","attributes":{"name":"Bob","seed":2,"group_id":500,"group_player_ids":[111]}}]}`),
+		[]byte(`{"data":[{"id":"1","type":"match","attributes":{"round":1,"state":"complete","group_id":500,"winner_id":110,"loser_id":111,"score_in_sets":[[2,0]]},
+			"relationships":{"player1":{"data":{"id":"110","type":"participant"}},"player2":{"data":{"id":"111","type":"participant"}}}}]}`),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	event := convertChallongeTournament(resp, DefaultNameSeparators).Events[0]
+	assert.Len(t, event.Phases, 2)
+	group := event.Phases[0].Groups[0]
+	assert.Equal(t, "500", group.ID)
+	assert.Equal(t, FormatDoubleElimination, group.Bracket.Format)
+	match := group.Bracket.Matches[0]
+	assert.Equal(t, "10", match.Player1ID)
+	assert.Equal(t, "11", match.Player2ID)
+	assert.Equal(t, "10", match.WinnerID)
+}
+
+func TestFormatChallongeScores(t *testing.T) {
+	assert.Equal(t, "3-1,0--1", formatChallongeScores([][]int{{3, 1}, {0, -1}}))
+	assert.Equal(t, "", formatChallongeScores(nil))
+}
+
+func TestFetchChallongeV2ClientCredentials(t *testing.T) {
+	fixtures := readChallongeV2Fixtures(t)
+	tokenRequests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/oauth/token" {
+			tokenRequests++
+			assert.NoError(t, r.ParseForm())
+			assert.Equal(t, "client_credentials", r.PostForm.Get("grant_type"))
+			assert.Equal(t, "id", r.PostForm.Get("client_id"))
+			assert.Equal(t, "secret", r.PostForm.Get("client_secret"))
+			assert.Equal(t, "tournaments:read", r.PostForm.Get("scope"))
+			w.Write([]byte(`{"access_token":"token","token_type":"Bearer","expires_in":3600}`))
+			return
+		}
+
+		assert.Equal(t, "v2", r.Header.Get("Authorization-Type"))
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		assert.Equal(t, "application/vnd.api+json", r.Header.Get("Content-Type"))
+		fixture, ok := fixtures[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(fixture)
+	}))
+	defer server.Close()
+
+	c := New(
+		WithChallongeAPIVersion(ChallongeV2),
+		WithChallongeBaseURL(server.URL+"/v2.1"),
+		WithChallongeTokenURL(server.URL+"/oauth/token"),
+		WithChallongeClientCredentials("id", "secret", "tournaments:read"),
+	)
+	b, err := c.FetchBracket("http://HSCSmashNE.challonge.com/MRA2_s4s_t16")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "Missouri River Arcadian - The Sequel: Smash4 Top 16", b.Name)
+	assert.Len(t, b.Players, 2)
+	assert.Len(t, b.Matches, 1)
+	// The token is reused until it expires
+	assert.Equal(t, 1, tokenRequests)
+}
+
+func TestFetchChallongeV2Pagination(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "v1", r.Header.Get("Authorization-Type"))
+		assert.Equal(t, "key", r.Header.Get("Authorization"))
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/participants.json") && r.URL.Query().Get("page") == "":
+			w.Write([]byte(`{"data":[{"id":"1","type":"participant","attributes":{"name":"CDK","seed":1}}],
+				"links":{"next":"` + server.URL + `/tournaments/abc/participants.json?page=2"}}`))
+		case strings.HasSuffix(r.URL.Path, "/participants.json"):
+			w.Write([]byte(`{"data":[{"id":"2","type":"participant","attributes":{"name":"Slime","seed":2}}],"links":{"next":null}}`))
+		case strings.HasSuffix(r.URL.Path, "/matches.json"):
+			w.Write([]byte(`{"data":[]}`))
+		default:
+			w.Write([]byte(`{"data":{"id":"3","type":"tournament","attributes":{"name":"abc"}}}`))
+		}
+	}))
+	defer server.Close()
+
+	c := New(
+		WithChallongeAPIVersion(ChallongeV2),
+		WithChallongeBaseURL(server.URL),
+		WithChallongeCredentials("user", "key"),
+	)
+	b, err := c.FetchBracket("http://challonge.com/abc")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Len(t, b.Players, 2)
+	assert.Equal(t, "CDK", b.Players[0].Name)
+	assert.Equal(t, "Slime", b.Players[1].Name)
+}
+
+func TestFetchChallongeV2PaginationStaysOnAPIHost(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/participants.json") {
+			w.Write([]byte(`{"data":[],"links":{"next":"https://evil.example.com/steal"}}`))
+			return
+		}
+		w.Write([]byte(`{"data":{"id":"3","type":"tournament","attributes":{"name":"abc"}}}`))
+	}))
+	defer server.Close()
+
+	c := New(
+		WithChallongeAPIVersion(ChallongeV2),
+		WithChallongeBaseURL(server.URL),
+		WithChallongeCredentials("user", "key"),
+	)
+	_, err := c.FetchBracket("http://challonge.com/abc")
+	assert.EqualError(t, err, `bracket: challonge pagination link "https://evil.example.com/steal" is not on `+server.URL+"/")
+}
+
+func TestChallongeTokenSourceRequestsOnce(t *testing.T) {
+	var mu sync.Mutex
+	tokenRequests := 0
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		tokenRequests++
+		mu.Unlock()
+		<-release
+		w.Write([]byte(`{"access_token":"token","expires_in":3600}`))
+	}))
+	defer server.Close()
+
+	c := New(WithChallongeTokenURL(server.URL), WithChallongeClientCredentials("id", "secret"))
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			token, err := c.challongeTokens.accessToken(context.Background(), c)
+			assert.NoError(t, err)
+			assert.Equal(t, "token", token)
+		}()
+	}
+
+	// a caller waiting on the request can give up without it finishing
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	time.Sleep(5 * time.Millisecond)
+	_, err := c.challongeTokens.accessToken(ctx, c)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+
+	close(release)
+	wg.Wait()
+	assert.Equal(t, 1, tokenRequests)
+}
+
+func TestFetchChallongeV2Errors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"errors":[{"status":401,"detail":"Invalid access token","source":{}}]}`))
+	}))
+	defer server.Close()
+
+	c := New(
+		WithChallongeAPIVersion(ChallongeV2),
+		WithChallongeBaseURL(server.URL),
+		WithChallongeAccessToken("expired"),
+	)
+	_, err := c.FetchBracket("http://challonge.com/abc")
+	assert.True(t, errors.Is(err, ErrUnauthorized))
+	var apiErr *APIError
+	assert.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, []string{"Invalid access token"}, apiErr.Messages)
+}
+
+func TestChallongeV2DefaultBaseURL(t *testing.T) {
+	assert.Equal(t, defaultChallongeV2BaseURL, New(WithChallongeAPIVersion(ChallongeV2)).challongeBaseURL)
+	assert.Equal(t, defaultChallongeBaseURL, New().challongeBaseURL)
+}
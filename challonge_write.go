@@ -0,0 +1,315 @@
+package bracket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+// ChallongeTournamentSettings describes a Challonge tournament to create
+// or update. Empty fields are left unchanged on update.
+type ChallongeTournamentSettings struct {
+	Name string
+	// URL is the tournament's slug, e.g. "xyfuz5c3" in
+	// http://challonge.com/xyfuz5c3.
+	URL string
+	// Subdomain is the organization hosting the tournament, if any.
+	Subdomain   string
+	Description string
+	// TournamentType is "single elimination", "double elimination",
+	// "round robin" or "swiss".
+	TournamentType string
+}
+
+func (s *ChallongeTournamentSettings) tournament() *challongeTournament {
+	return &challongeTournament{
+		Name:           s.Name,
+		URL:            s.URL,
+		Subdomain:      s.Subdomain,
+		Description:    s.Description,
+		TournamentType: s.TournamentType,
+	}
+}
+
+// ChallongeParticipant describes a participant to add to a Challonge
+// tournament.
+type ChallongeParticipant struct {
+	Name string
+	// Seed is optional. Participants without one are seeded last.
+	Seed int
+}
+
+// challongeWrite makes a write request against the v1 API and decodes the
+// response into out, if non-nil. Cached reads of the tournament are
+// dropped so the next fetch sees the change.
+func challongeWrite(ctx context.Context, c *Client, method, url, path string, body, out interface{}) error {
+	if c.challongeAPIVersion != ChallongeV1 {
+		return fmt.Errorf("%w: Challonge write operations require API v1", ErrNotSupported)
+	}
+
+	var reqBody []byte
+	if body != nil {
+		var err error
+		reqBody, err = json.Marshal(body)
+		if err != nil {
+			return err
+		}
+	}
+	resp, err := c.do(ctx, &apiRequest{
+		Provider: "challonge",
+		Method:   method,
+		URL:      c.challongeBaseURL + "tournaments/" + getChallongeHash(url) + path,
+		Body:     reqBody,
+		Setup: func(req *http.Request) {
+			c.setChallongeAuth(req)
+			req.Header.Set("Content-Type", "application/json")
+		},
+		DecodeErrors: decodeChallongeErrors,
+	})
+	if c.cachePolicy.Cache != nil {
+		c.cachePolicy.Cache.Delete(c.cacheKey(getChallongeAPIURL(c.challongeBaseURL, url)))
+	}
+	if err != nil {
+		return err
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(resp.Body, out)
+}
+
+// challongeTournamentAction runs a write request that returns the whole
+// tournament, and converts it to a bracket.
+func challongeTournamentAction(ctx context.Context, c *Client, method, url, path string, body interface{}) (*Bracket, error) {
+	var resp challongeAPIResponse
+	err := challongeWrite(ctx, c, method, url, path+"?include_participants=1&include_matches=1", body, &resp)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Tournament == nil {
+		return nil, fmt.Errorf("bracket: challonge returned no tournament")
+	}
+	return convertChallongeData(&resp, c.challongeNameSeparators), nil
+}
+
+func challongeID(id string) (int, error) {
+	n, err := strconv.Atoi(id)
+	if err != nil {
+		return 0, fmt.Errorf("bracket: invalid Challonge ID %q", id)
+	}
+	return n, nil
+}
+
+// CreateChallongeTournament creates a Challonge tournament and returns it
+// as an empty bracket.
+func (c *Client) CreateChallongeTournament(ctx context.Context, s *ChallongeTournamentSettings) (*Bracket, error) {
+	if c.challongeAPIVersion != ChallongeV1 {
+		return nil, fmt.Errorf("%w: Challonge write operations require API v1", ErrNotSupported)
+	}
+	body, err := json.Marshal(challongeAPIResponse{s.tournament()})
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.do(ctx, &apiRequest{
+		Provider: "challonge",
+		Method:   "POST",
+		URL:      c.challongeBaseURL + "tournaments.json",
+		Body:     body,
+		Setup: func(req *http.Request) {
+			c.setChallongeAuth(req)
+			req.Header.Set("Content-Type", "application/json")
+		},
+		DecodeErrors: decodeChallongeErrors,
+	})
+	if err != nil {
+		return nil, err
+	}
+	decoded, err := decodeChallongeData(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	return convertChallongeData(decoded, c.challongeNameSeparators), nil
+}
+
+// UpdateChallongeTournament changes the settings of the Challonge
+// tournament at url.
+func (c *Client) UpdateChallongeTournament(ctx context.Context, url string, s *ChallongeTournamentSettings) (*Bracket, error) {
+	return challongeTournamentAction(ctx, c, "PUT", url, ".json", challongeAPIResponse{s.tournament()})
+}
+
+// DeleteChallongeTournament deletes the Challonge tournament at url.
+func (c *Client) DeleteChallongeTournament(ctx context.Context, url string) error {
+	return challongeWrite(ctx, c, "DELETE", url, ".json", nil, nil)
+}
+
+// StartChallongeTournament starts the Challonge tournament at url, opening
+// its first matches.
+func (c *Client) StartChallongeTournament(ctx context.Context, url string) (*Bracket, error) {
+	return challongeTournamentAction(ctx, c, "POST", url, "/start.json", nil)
+}
+
+// ResetChallongeTournament resets the Challonge tournament at url,
+// clearing all scores so it can be started again.
+func (c *Client) ResetChallongeTournament(ctx context.Context, url string) (*Bracket, error) {
+	return challongeTournamentAction(ctx, c, "POST", url, "/reset.json", nil)
+}
+
+// FinalizeChallongeTournament finalizes the results of the Challonge
+// tournament at url once all of its matches are complete.
+func (c *Client) FinalizeChallongeTournament(ctx context.Context, url string) (*Bracket, error) {
+	return challongeTournamentAction(ctx, c, "POST", url, "/finalize.json", nil)
+}
+
+// AddChallongeParticipants adds participants to the Challonge tournament
+// at url in a single request and returns them as players.
+func (c *Client) AddChallongeParticipants(ctx context.Context, url string, participants []*ChallongeParticipant) ([]*Player, error) {
+	body := struct {
+		Participants []*challongeParticipant `json:"participants"`
+	}{}
+	for _, p := range participants {
+		body.Participants = append(body.Participants, &challongeParticipant{Name: p.Name, Seed: p.Seed})
+	}
+	var resp []*challongeParticipantWrap
+	if err := challongeWrite(ctx, c, "POST", url, "/participants/bulk_add.json", body, &resp); err != nil {
+		return nil, err
+	}
+	return convertChallongePlayers(resp, c.challongeNameSeparators), nil
+}
+
+// RemoveChallongeParticipants removes players from the Challonge
+// tournament at url. Once the tournament has started, removed players
+// forfeit their remaining matches instead.
+func (c *Client) RemoveChallongeParticipants(ctx context.Context, url string, playerIDs []string) error {
+	for _, id := range playerIDs {
+		participantID, err := challongeID(id)
+		if err != nil {
+			return err
+		}
+		path := "/participants/" + strconv.Itoa(participantID) + ".json"
+		if err := challongeWrite(ctx, c, "DELETE", url, path, nil, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReseedChallongeParticipant moves a player to a new seed in the Challonge
+// tournament at url, shifting the other players to make room.
+func (c *Client) ReseedChallongeParticipant(ctx context.Context, url, playerID string, seed int) (*Player, error) {
+	participantID, err := challongeID(playerID)
+	if err != nil {
+		return nil, err
+	}
+	body := challongeParticipantWrap{&challongeParticipant{Seed: seed}}
+	var resp challongeParticipantWrap
+	path := "/participants/" + strconv.Itoa(participantID) + ".json"
+	if err := challongeWrite(ctx, c, "PUT", url, path, body, &resp); err != nil {
+		return nil, err
+	}
+	return convertChallongePlayers([]*challongeParticipantWrap{&resp}, c.challongeNameSeparators)[0], nil
+}
+
+// RandomizeChallongeSeeds shuffles the seeds of every player in the
+// Challonge tournament at url.
+func (c *Client) RandomizeChallongeSeeds(ctx context.Context, url string) ([]*Player, error) {
+	var resp []*challongeParticipantWrap
+	if err := challongeWrite(ctx, c, "POST", url, "/participants/randomize.json", nil, &resp); err != nil {
+		return nil, err
+	}
+	return convertChallongePlayers(resp, c.challongeNameSeparators), nil
+}
+
+// ReportMatch implements MatchReporter. Challonge has no flag for
+// disqualifications, so unless scores are given the loser of a
+// disqualification is recorded with a score of -1.
+func (p *challongeProvider) ReportMatch(ctx context.Context, url, matchID string, r *MatchReport) (*Match, error) {
+	if err := r.validate(); err != nil {
+		return nil, err
+	}
+	id, err := challongeID(matchID)
+	if err != nil {
+		return nil, err
+	}
+	m := &challongeMatch{ScoresCsv: formatChallongeGameScores(r.Scores)}
+	if r.WinnerID != "" {
+		if m.WinnerID, err = challongeID(r.WinnerID); err != nil {
+			return nil, err
+		}
+	}
+	if r.Disqualified && len(r.Scores) == 0 {
+		current, err := fetchChallongeMatch(ctx, p.client, url, id)
+		if err != nil {
+			return nil, err
+		}
+		if current.Player1ID == m.WinnerID {
+			m.ScoresCsv = "0--1"
+		} else {
+			m.ScoresCsv = "-1-0"
+		}
+	}
+
+	return challongeMatchAction(ctx, p.client, "PUT", url, id, ".json", challongeMatchWrap{m})
+}
+
+// MarkMatchInProgress implements MatchUpdater.
+func (p *challongeProvider) MarkMatchInProgress(ctx context.Context, url, matchID string) (*Match, error) {
+	id, err := challongeID(matchID)
+	if err != nil {
+		return nil, err
+	}
+	return challongeMatchAction(ctx, p.client, "POST", url, id, "/mark_as_underway.json", nil)
+}
+
+// ResetMatch implements MatchUpdater by reopening the match, which also
+// clears the matches that followed from it.
+func (p *challongeProvider) ResetMatch(ctx context.Context, url, matchID string) (*Match, error) {
+	id, err := challongeID(matchID)
+	if err != nil {
+		return nil, err
+	}
+	return challongeMatchAction(ctx, p.client, "POST", url, id, "/reopen.json", nil)
+}
+
+func challongeMatchAction(ctx context.Context, c *Client, method, url string, id int, path string, body interface{}) (*Match, error) {
+	var resp challongeMatchWrap
+	path = "/matches/" + strconv.Itoa(id) + path
+	if err := challongeWrite(ctx, c, method, url, path, body, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Match == nil {
+		return nil, fmt.Errorf("%w: challonge match %d", ErrNotFound, id)
+	}
+	return convertChallongeMatches([]*challongeMatchWrap{&resp})[0], nil
+}
+
+// fetchChallongeMatch gets the current state of a match, skipping the cache
+// since it's used to decide what to write.
+func fetchChallongeMatch(ctx context.Context, c *Client, url string, id int) (*challongeMatch, error) {
+	resp, err := c.do(ctx, &apiRequest{
+		Provider:     "challonge",
+		URL:          c.challongeBaseURL + "tournaments/" + getChallongeHash(url) + "/matches/" + strconv.Itoa(id) + ".json",
+		Setup:        c.setChallongeAuth,
+		DecodeErrors: decodeChallongeErrors,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var m challongeMatchWrap
+	if err := json.Unmarshal(resp.Body, &m); err != nil {
+		return nil, err
+	}
+	if m.Match == nil {
+		return nil, fmt.Errorf("%w: challonge match %d", ErrNotFound, id)
+	}
+	return m.Match, nil
+}
+
+func formatChallongeGameScores(scores []GameScore) string {
+	sets := make([][]int, len(scores))
+	for i, s := range scores {
+		sets[i] = []int{s.Player1Score, s.Player2Score}
+	}
+	return formatChallongeScores(sets)
+}
@@ -0,0 +1,255 @@
+package bracket
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type challongeWriteRequest struct {
+	Method string
+	Path   string
+	Body   string
+}
+
+func newChallongeWriteTestServer(t *testing.T, response string) (*httptest.Server, *[]challongeWriteRequest) {
+	var requests []challongeWriteRequest
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		user, key, _ := r.BasicAuth()
+		assert.Equal(t, "user", user)
+		assert.Equal(t, "key", key)
+		requests = append(requests, challongeWriteRequest{r.Method, r.URL.RequestURI(), string(body)})
+		w.Write([]byte(response))
+	}))
+	return ts, &requests
+}
+
+func newChallongeWriteTestClient(ts *httptest.Server) *Client {
+	return New(
+		WithChallongeCredentials("user", "key"),
+		WithChallongeBaseURL(ts.URL),
+	)
+}
+
+func TestCreateChallongeTournament(t *testing.T) {
+	ts, requests := newChallongeWriteTestServer(t, `{"tournament":{"id":1,"name":"Weekly","state":"pending","full_challonge_url":"http://challonge.com/weekly1"}}`)
+	defer ts.Close()
+
+	b, err := newChallongeWriteTestClient(ts).CreateChallongeTournament(context.Background(), &ChallongeTournamentSettings{
+		Name:           "Weekly",
+		URL:            "weekly1",
+		TournamentType: "double elimination",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "Weekly", b.Name)
+	assert.Equal(t, "http://challonge.com/weekly1", b.URL)
+	assert.Equal(t, []challongeWriteRequest{{
+		"POST",
+		"/tournaments.json",
+		`{"tournament":{"name":"Weekly","url":"weekly1","tournament_type":"double elimination"}}`,
+	}}, *requests)
+}
+
+func TestStartChallongeTournament(t *testing.T) {
+	b, err := ioutil.ReadFile("testdata/challonge.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts, requests := newChallongeWriteTestServer(t, string(b))
+	defer ts.Close()
+
+	bracket, err := newChallongeWriteTestClient(ts).StartChallongeTournament(context.Background(), "http://HSCSmashNE.challonge.com/MRA2_s4s_t16")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 2, len(bracket.Players))
+	assert.Equal(t, []challongeWriteRequest{{
+		"POST",
+		"/tournaments/HSCSmashNE-MRA2_s4s_t16/start.json?include_participants=1&include_matches=1",
+		"",
+	}}, *requests)
+}
+
+func TestAddChallongeParticipants(t *testing.T) {
+	ts, requests := newChallongeWriteTestServer(t, `[{"participant":{"id":10,"name":"Alice","display_name":"Alice","seed":1}},{"participant":{"id":11,"name":"Bob","display_name":"Bob","seed":2}}]`)
+	defer ts.Close()
+
+	players, err := newChallongeWriteTestClient(ts).AddChallongeParticipants(context.Background(), "http://challonge.com/weekly1", []*ChallongeParticipant{
+		{Name: "Alice", Seed: 1},
+		{Name: "Bob"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []*Player{
+		{ID: "10", Name: "Alice", Seed: 1, GamerTag: "Alice"},
+		{ID: "11", Name: "Bob", Seed: 2, GamerTag: "Bob"},
+	}, players)
+	assert.Equal(t, []challongeWriteRequest{{
+		"POST",
+		"/tournaments/weekly1/participants/bulk_add.json",
+		`{"participants":[{"name":"Alice","seed":1},{"name":"Bob"}]}`,
+	}}, *requests)
+}
+
+func TestRemoveChallongeParticipants(t *testing.T) {
+	ts, requests := newChallongeWriteTestServer(t, `{}`)
+	defer ts.Close()
+
+	err := newChallongeWriteTestClient(ts).RemoveChallongeParticipants(context.Background(), "http://challonge.com/weekly1", []string{"10", "11"})
+	assert.NoError(t, err)
+	assert.Equal(t, []challongeWriteRequest{
+		{"DELETE", "/tournaments/weekly1/participants/10.json", ""},
+		{"DELETE", "/tournaments/weekly1/participants/11.json", ""},
+	}, *requests)
+
+	err = newChallongeWriteTestClient(ts).RemoveChallongeParticipants(context.Background(), "http://challonge.com/weekly1", []string{"abc"})
+	assert.Error(t, err)
+}
+
+func TestReseedChallongeParticipant(t *testing.T) {
+	ts, requests := newChallongeWriteTestServer(t, `{"participant":{"id":11,"display_name":"Bob","seed":1}}`)
+	defer ts.Close()
+
+	p, err := newChallongeWriteTestClient(ts).ReseedChallongeParticipant(context.Background(), "http://challonge.com/weekly1", "11", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, &Player{ID: "11", Name: "Bob", Seed: 1, GamerTag: "Bob"}, p)
+	assert.Equal(t, []challongeWriteRequest{
+		{"PUT", "/tournaments/weekly1/participants/11.json", `{"participant":{"seed":1}}`},
+	}, *requests)
+}
+
+func TestReportChallongeMatch(t *testing.T) {
+	ts, requests := newChallongeWriteTestServer(t, `{"match":{"id":5,"state":"complete","player1_id":10,"player2_id":11,"winner_id":10,"loser_id":11,"scores_csv":"3-1,2-3,3-0"}}`)
+	defer ts.Close()
+
+	m, err := newChallongeWriteTestClient(ts).ReportMatch(context.Background(), "http://challonge.com/weekly1", "5", &MatchReport{
+		WinnerID: "10",
+		Scores:   []GameScore{{3, 1}, {2, 3}, {3, 0}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, MatchStateComplete, m.State)
+	assert.Equal(t, "10", m.WinnerID)
+	assert.Equal(t, 8, m.Player1Score)
+	assert.Equal(t, []challongeWriteRequest{
+		{"PUT", "/tournaments/weekly1/matches/5.json", `{"match":{"winner_id":10,"scores_csv":"3-1,2-3,3-0"}}`},
+	}, *requests)
+}
+
+func TestChallongeWriteInvalidatesCache(t *testing.T) {
+	ts, _ := newChallongeWriteTestServer(t, `{}`)
+	defer ts.Close()
+
+	cache := NewMemoryCache(10)
+	c := New(
+		WithChallongeCredentials("user", "key"),
+		WithChallongeBaseURL(ts.URL),
+		WithCache(CachePolicy{Cache: cache}),
+	)
+	url := "http://challonge.com/weekly1"
+	cache.Set(c.cacheKey(getChallongeAPIURL(c.challongeBaseURL, url)), &CachedResponse{Body: []byte(`{}`)})
+
+	assert.NoError(t, c.DeleteChallongeTournament(context.Background(), url))
+	assert.Equal(t, 0, cache.Len())
+}
+
+func TestChallongeWriteRequiresV1(t *testing.T) {
+	c := New(WithChallongeAPIVersion(ChallongeV2), WithChallongeAccessToken("token"))
+	_, err := c.StartChallongeTournament(context.Background(), "http://challonge.com/weekly1")
+	assert.True(t, errors.Is(err, ErrNotSupported))
+}
+
+func TestReportMatchUnsupportedProvider(t *testing.T) {
+	c := New()
+	c.RegisterProvider(&fakeProvider{name: "fake", prefix: "fake://"}, 0)
+	_, err := c.ReportMatch(context.Background(), "fake://bracket", "1", &MatchReport{})
+	assert.True(t, errors.Is(err, ErrNotSupported))
+
+	_, err = c.ReportMatch(context.Background(), "http://example.com", "1", &MatchReport{})
+	assert.True(t, errors.Is(err, ErrUnsupportedURL))
+}
+
+func TestReportChallongeDisqualification(t *testing.T) {
+	var requests []challongeWriteRequest
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		requests = append(requests, challongeWriteRequest{r.Method, r.URL.RequestURI(), string(body)})
+		w.Write([]byte(`{"match":{"id":5,"state":"complete","player1_id":10,"player2_id":11,"winner_id":11,"loser_id":10,"scores_csv":"-1-0"}}`))
+	}))
+	defer ts.Close()
+
+	_, err := newChallongeWriteTestClient(ts).ReportMatch(context.Background(), "http://challonge.com/weekly1", "5", &MatchReport{
+		WinnerID:     "11",
+		Disqualified: true,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []challongeWriteRequest{
+		{"GET", "/tournaments/weekly1/matches/5.json", ""},
+		{"PUT", "/tournaments/weekly1/matches/5.json", `{"match":{"winner_id":11,"scores_csv":"-1-0"}}`},
+	}, requests)
+}
+
+func TestReportChallongeDisqualificationSkipsCache(t *testing.T) {
+	ts, requests := newChallongeWriteTestServer(t, `{"match":{"id":5,"state":"complete","player1_id":10,"player2_id":11,"winner_id":11,"loser_id":10,"scores_csv":"-1-0"}}`)
+	defer ts.Close()
+
+	cache := NewMemoryCache(10)
+	c := New(
+		WithChallongeCredentials("user", "key"),
+		WithChallongeBaseURL(ts.URL),
+		WithCache(CachePolicy{Cache: cache, TTL: time.Hour}),
+	)
+	// a stale entry with the players the other way around
+	cache.Set(c.cacheKey(ts.URL+"/tournaments/weekly1/matches/5.json"), &CachedResponse{
+		Body:     []byte(`{"match":{"id":5,"player1_id":11,"player2_id":10}}`),
+		StoredAt: time.Now(),
+	})
+
+	_, err := c.ReportMatch(context.Background(), "http://challonge.com/weekly1", "5", &MatchReport{
+		WinnerID:     "11",
+		Disqualified: true,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, *requests, 2)
+	assert.Equal(t, `{"match":{"winner_id":11,"scores_csv":"-1-0"}}`, (*requests)[1].Body)
+}
+
+func TestReportDisqualificationWithoutWinner(t *testing.T) {
+	ts, requests := newChallongeWriteTestServer(t, `{}`)
+	defer ts.Close()
+
+	_, err := newChallongeWriteTestClient(ts).ReportMatch(context.Background(), "http://challonge.com/weekly1", "5", &MatchReport{
+		Disqualified: true,
+	})
+	assert.EqualError(t, err, "bracket: a disqualification needs a WinnerID")
+	assert.Empty(t, *requests)
+}
+
+func TestUpdateChallongeMatch(t *testing.T) {
+	ts, requests := newChallongeWriteTestServer(t, `{"match":{"id":5,"state":"open","player1_id":10,"player2_id":11,"scores_csv":"1-0"}}`)
+	defer ts.Close()
+
+	c := newChallongeWriteTestClient(ts)
+	m, err := c.MarkMatchInProgress(context.Background(), "http://challonge.com/weekly1", "5")
+	assert.NoError(t, err)
+	assert.Equal(t, MatchStateOpen, m.State)
+	_, err = c.ResetMatch(context.Background(), "http://challonge.com/weekly1", "5")
+	assert.NoError(t, err)
+	assert.Equal(t, []challongeWriteRequest{
+		{"POST", "/tournaments/weekly1/matches/5/mark_as_underway.json", ""},
+		{"POST", "/tournaments/weekly1/matches/5/reopen.json", ""},
+	}, *requests)
+}
@@ -0,0 +1,171 @@
+package bracket
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// BracketDiff lists the differences between two snapshots of a bracket.
+// Players and matches are matched up by ID.
+type BracketDiff struct {
+	PlayersAdded   []*Player
+	PlayersRemoved []*Player
+	SeedChanges    []SeedChange
+	MatchesAdded   []*Match
+	MatchesRemoved []*Match
+	// MatchReassignments lists matches where a player already placed in
+	// the match was replaced by someone else.
+	MatchReassignments []MatchChange
+	// OverturnedWinners lists matches whose winner changed after one had
+	// been decided.
+	OverturnedWinners []MatchChange
+	// ScoreCorrections lists matches whose score changed after a winner
+	// had been decided.
+	ScoreCorrections []MatchChange
+
+	// names maps player IDs from both snapshots to names, for String.
+	names map[string]string
+}
+
+// SeedChange records a player whose seed changed.
+type SeedChange struct {
+	Player  *Player
+	OldSeed int
+	NewSeed int
+}
+
+// MatchChange holds a match as it was in each snapshot.
+type MatchChange struct {
+	Old *Match
+	New *Match
+}
+
+// Diff compares two snapshots of a bracket and returns what changed from
+// a to b.
+func Diff(a, b *Bracket) *BracketDiff {
+	d := &BracketDiff{names: make(map[string]string)}
+
+	oldPlayers := make(map[string]*Player, len(a.Players))
+	for _, p := range a.Players {
+		oldPlayers[p.ID] = p
+		d.names[p.ID] = p.Name
+	}
+	newPlayers := make(map[string]*Player, len(b.Players))
+	for _, p := range b.Players {
+		newPlayers[p.ID] = p
+		d.names[p.ID] = p.Name
+	}
+	for _, p := range b.Players {
+		old, ok := oldPlayers[p.ID]
+		if !ok {
+			d.PlayersAdded = append(d.PlayersAdded, p)
+		} else if old.Seed != p.Seed {
+			d.SeedChanges = append(d.SeedChanges, SeedChange{p, old.Seed, p.Seed})
+		}
+	}
+	for _, p := range a.Players {
+		if _, ok := newPlayers[p.ID]; !ok {
+			d.PlayersRemoved = append(d.PlayersRemoved, p)
+		}
+	}
+
+	oldMatches := make(map[string]*Match, len(a.Matches))
+	for _, m := range a.Matches {
+		oldMatches[m.ID] = m
+	}
+	newMatches := make(map[string]*Match, len(b.Matches))
+	for _, m := range b.Matches {
+		newMatches[m.ID] = m
+	}
+	for _, m := range b.Matches {
+		old, ok := oldMatches[m.ID]
+		if !ok {
+			d.MatchesAdded = append(d.MatchesAdded, m)
+			continue
+		}
+		change := MatchChange{old, m}
+		if (hasPlayer(old.Player1ID) && old.Player1ID != m.Player1ID) ||
+			(hasPlayer(old.Player2ID) && old.Player2ID != m.Player2ID) {
+			d.MatchReassignments = append(d.MatchReassignments, change)
+		}
+		if hasPlayer(old.WinnerID) && old.WinnerID != m.WinnerID {
+			d.OverturnedWinners = append(d.OverturnedWinners, change)
+		}
+		if hasPlayer(old.WinnerID) &&
+			(old.Player1Score != m.Player1Score || old.Player2Score != m.Player2Score) {
+			d.ScoreCorrections = append(d.ScoreCorrections, change)
+		}
+	}
+	for _, m := range a.Matches {
+		if _, ok := newMatches[m.ID]; !ok {
+			d.MatchesRemoved = append(d.MatchesRemoved, m)
+		}
+	}
+
+	return d
+}
+
+// Empty reports whether the snapshots had no differences.
+func (d *BracketDiff) Empty() bool {
+	return len(d.PlayersAdded) == 0 &&
+		len(d.PlayersRemoved) == 0 &&
+		len(d.SeedChanges) == 0 &&
+		len(d.MatchesAdded) == 0 &&
+		len(d.MatchesRemoved) == 0 &&
+		len(d.MatchReassignments) == 0 &&
+		len(d.OverturnedWinners) == 0 &&
+		len(d.ScoreCorrections) == 0
+}
+
+// String renders the diff with one change per line, prefixed with "+" for
+// additions, "-" for removals and "~" for modifications.
+func (d *BracketDiff) String() string {
+	var buf bytes.Buffer
+	for _, p := range d.PlayersAdded {
+		fmt.Fprintf(&buf, "+ player %s (seed %d)\n", p.Name, p.Seed)
+	}
+	for _, p := range d.PlayersRemoved {
+		fmt.Fprintf(&buf, "- player %s (seed %d)\n", p.Name, p.Seed)
+	}
+	for _, s := range d.SeedChanges {
+		fmt.Fprintf(&buf, "~ player %s: seed %d -> %d\n", s.Player.Name, s.OldSeed, s.NewSeed)
+	}
+	for _, m := range d.MatchesAdded {
+		fmt.Fprintf(&buf, "+ match %s: %s\n", matchLabel(m), d.players(m))
+	}
+	for _, m := range d.MatchesRemoved {
+		fmt.Fprintf(&buf, "- match %s: %s\n", matchLabel(m), d.players(m))
+	}
+	for _, c := range d.MatchReassignments {
+		fmt.Fprintf(&buf, "~ match %s: players %s -> %s\n", matchLabel(c.New), d.players(c.Old), d.players(c.New))
+	}
+	for _, c := range d.OverturnedWinners {
+		fmt.Fprintf(&buf, "~ match %s: winner %s -> %s\n", matchLabel(c.New), d.name(c.Old.WinnerID), d.name(c.New.WinnerID))
+	}
+	for _, c := range d.ScoreCorrections {
+		fmt.Fprintf(&buf, "~ match %s: score %d-%d -> %d-%d\n", matchLabel(c.New),
+			c.Old.Player1Score, c.Old.Player2Score, c.New.Player1Score, c.New.Player2Score)
+	}
+	return buf.String()
+}
+
+func (d *BracketDiff) name(id string) string {
+	if !hasPlayer(id) {
+		return "(none)"
+	}
+	if name, ok := d.names[id]; ok && name != "" {
+		return name
+	}
+	return id
+}
+
+func (d *BracketDiff) players(m *Match) string {
+	return d.name(m.Player1ID) + " vs " + d.name(m.Player2ID)
+}
+
+func matchLabel(m *Match) string {
+	if m.Identifier != "" {
+		return m.Identifier
+	}
+	return m.ID
+}
@@ -0,0 +1,74 @@
+package bracket
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	a := &Bracket{
+		Players: []*Player{
+			{ID: "1", Name: "CDK", Seed: 7},
+			{ID: "2", Name: "A-Dar", Seed: 70},
+			{ID: "3", Name: "Slime", Seed: 122},
+		},
+		Matches: []*Match{
+			{ID: "10", Identifier: "A", Player1ID: "1", Player2ID: "3", WinnerID: "1", LoserID: "3", Player1Score: 2, Player2Score: 0},
+			{ID: "11", Identifier: "B", Player1ID: "1", Player2ID: "0"},
+			{ID: "12", Identifier: "C", Player1ID: "2", Player2ID: "3"},
+		},
+	}
+	b := &Bracket{
+		Players: []*Player{
+			{ID: "1", Name: "CDK", Seed: 5},
+			{ID: "3", Name: "Slime", Seed: 122},
+			{ID: "4", Name: "Hite", Seed: 16},
+		},
+		Matches: []*Match{
+			{ID: "10", Identifier: "A", Player1ID: "1", Player2ID: "3", WinnerID: "3", LoserID: "1", Player1Score: 1, Player2Score: 2},
+			// Filling an empty slot is normal progression, not a reassignment
+			{ID: "11", Identifier: "B", Player1ID: "1", Player2ID: "4"},
+			{ID: "13", Identifier: "D", Player1ID: "4", Player2ID: "3"},
+		},
+	}
+
+	d := Diff(a, b)
+	assert.False(t, d.Empty())
+	assert.Equal(t, []*Player{b.Players[2]}, d.PlayersAdded)
+	assert.Equal(t, []*Player{a.Players[1]}, d.PlayersRemoved)
+	assert.Equal(t, []SeedChange{{b.Players[0], 7, 5}}, d.SeedChanges)
+	assert.Equal(t, []*Match{b.Matches[2]}, d.MatchesAdded)
+	assert.Equal(t, []*Match{a.Matches[2]}, d.MatchesRemoved)
+	assert.Empty(t, d.MatchReassignments)
+	assert.Equal(t, []MatchChange{{a.Matches[0], b.Matches[0]}}, d.OverturnedWinners)
+	assert.Equal(t, []MatchChange{{a.Matches[0], b.Matches[0]}}, d.ScoreCorrections)
+
+	assert.Equal(t, `+ player Hite (seed 16)
+- player A-Dar (seed 70)
+~ player CDK: seed 7 -> 5
++ match D: Hite vs Slime
+- match C: A-Dar vs Slime
+~ match A: winner CDK -> Slime
+~ match A: score 2-0 -> 1-2
+`, d.String())
+}
+
+func TestDiffReassignment(t *testing.T) {
+	a := &Bracket{Matches: []*Match{{ID: "10", Player1ID: "1", Player2ID: "2"}}}
+	b := &Bracket{Matches: []*Match{{ID: "10", Player1ID: "1", Player2ID: "3"}}}
+
+	d := Diff(a, b)
+	assert.Equal(t, []MatchChange{{a.Matches[0], b.Matches[0]}}, d.MatchReassignments)
+	assert.Equal(t, "~ match 10: players 1 vs 2 -> 1 vs 3\n", d.String())
+}
+
+func TestDiffIdentical(t *testing.T) {
+	a := &Bracket{
+		Players: []*Player{{ID: "1", Name: "CDK", Seed: 7}},
+		Matches: []*Match{{ID: "10", Player1ID: "1", Player2ID: "2", WinnerID: "1"}},
+	}
+	d := Diff(a, a)
+	assert.True(t, d.Empty())
+	assert.Equal(t, "", d.String())
+}
@@ -0,0 +1,75 @@
+package bracket
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrUnsupportedURL is returned when no provider matches a URL.
+	ErrUnsupportedURL = errors.New("bracket: unsupported URL")
+	// ErrNotFound is returned when the web service has no bracket for a URL.
+	ErrNotFound = errors.New("bracket: not found")
+	// ErrUnauthorized is returned when the web service rejects the
+	// client's credentials.
+	ErrUnauthorized = errors.New("bracket: unauthorized")
+	// ErrRateLimited is returned when the web service is throttling the
+	// client.
+	ErrRateLimited = errors.New("bracket: rate limited")
+	// ErrNotSupported is returned when a provider or API version can't
+	// perform the requested operation.
+	ErrNotSupported = errors.New("bracket: not supported")
+)
+
+// maxBodyExcerpt is the most bytes of a response body kept on an APIError.
+const maxBodyExcerpt = 512
+
+// APIError is returned when a web service responds with an unsuccessful
+// HTTP status. It matches ErrNotFound, ErrUnauthorized or ErrRateLimited
+// under errors.Is when the status code corresponds to one of them.
+type APIError struct {
+	// Provider is the name of the provider that made the request.
+	Provider string
+	// StatusCode is the HTTP status code of the response.
+	StatusCode int
+	// Body is the start of the response body, for debugging.
+	Body string
+	// Messages holds any error messages the web service returned in a
+	// structured form.
+	Messages []string
+}
+
+func newAPIError(provider string, statusCode int, body []byte, messages []string) *APIError {
+	excerpt := body
+	if len(excerpt) > maxBodyExcerpt {
+		excerpt = excerpt[:maxBodyExcerpt]
+	}
+	return &APIError{
+		Provider:   provider,
+		StatusCode: statusCode,
+		Body:       string(excerpt),
+		Messages:   messages,
+	}
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("bracket: %s returned HTTP %d", e.Provider, e.StatusCode)
+	if len(e.Messages) > 0 {
+		msg += ": " + strings.Join(e.Messages, "; ")
+	}
+	return msg
+}
+
+// Is reports whether the error's status code corresponds to target.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == 404
+	case ErrUnauthorized:
+		return e.StatusCode == 401 || e.StatusCode == 403
+	case ErrRateLimited:
+		return e.StatusCode == 429
+	}
+	return false
+}
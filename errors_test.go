@@ -0,0 +1,53 @@
+package bracket
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIErrorIs(t *testing.T) {
+	cases := []struct {
+		status int
+		target error
+	}{
+		{404, ErrNotFound},
+		{401, ErrUnauthorized},
+		{403, ErrUnauthorized},
+		{429, ErrRateLimited},
+	}
+	for _, c := range cases {
+		err := fmt.Errorf("wrapped: %w", newAPIError("challonge", c.status, nil, nil))
+		assert.True(t, errors.Is(err, c.target), "status %d", c.status)
+	}
+
+	err := newAPIError("smashgg", 500, nil, nil)
+	assert.False(t, errors.Is(err, ErrNotFound))
+	assert.False(t, errors.Is(err, ErrUnauthorized))
+	assert.False(t, errors.Is(err, ErrRateLimited))
+
+	var apiErr *APIError
+	assert.True(t, errors.As(fmt.Errorf("wrapped: %w", err), &apiErr))
+	assert.Equal(t, "smashgg", apiErr.Provider)
+	assert.Equal(t, 500, apiErr.StatusCode)
+}
+
+func TestAPIErrorBodyExcerpt(t *testing.T) {
+	body := []byte(strings.Repeat("x", maxBodyExcerpt*2))
+	err := newAPIError("smashgg", 500, body, nil)
+	assert.Len(t, err.Body, maxBodyExcerpt)
+}
+
+func TestAPIErrorMessage(t *testing.T) {
+	body := []byte(`{"errors":["Name can't be blank","URL is already taken"]}`)
+	err := newAPIError("challonge", 422, body, decodeChallongeErrors(body))
+	assert.Equal(t, []string{"Name can't be blank", "URL is already taken"}, err.Messages)
+	assert.Equal(t, "bracket: challonge returned HTTP 422: Name can't be blank; URL is already taken", err.Error())
+}
+
+func TestDecodeChallongeErrorsNotJSON(t *testing.T) {
+	assert.Nil(t, decodeChallongeErrors([]byte("<html>Not Found</html>")))
+}
@@ -0,0 +1,178 @@
+package bracket
+
+// BracketFormat is the kind of bracket a tournament uses.
+type BracketFormat string
+
+const (
+	// FormatUnknown means the service didn't say what format the bracket
+	// uses.
+	FormatUnknown BracketFormat = ""
+	// FormatSingleElimination is a single elimination bracket.
+	FormatSingleElimination BracketFormat = "single_elimination"
+	// FormatDoubleElimination is a double elimination bracket, with a
+	// losers bracket and grand finals.
+	FormatDoubleElimination BracketFormat = "double_elimination"
+	// FormatRoundRobin is a round robin pool, where every player plays
+	// every other.
+	FormatRoundRobin BracketFormat = "round_robin"
+	// FormatSwiss is a swiss bracket, where players with similar records
+	// are paired each round.
+	FormatSwiss BracketFormat = "swiss"
+	// FormatOther is any other format, such as free for all.
+	FormatOther BracketFormat = "other"
+)
+
+// GrandFinalsModifier is how the grand finals of a double elimination
+// bracket are played.
+type GrandFinalsModifier string
+
+const (
+	// GrandFinalsWithReset plays a second grand final if the losers
+	// bracket champion wins the first.
+	GrandFinalsWithReset GrandFinalsModifier = "with_reset"
+	// GrandFinalsSingleMatch plays a single grand final.
+	GrandFinalsSingleMatch GrandFinalsModifier = "single_match"
+	// GrandFinalsSkip plays no grand finals, so the winners bracket
+	// champion wins the bracket.
+	GrandFinalsSkip GrandFinalsModifier = "skip"
+)
+
+// FormatSettings holds the settings that only apply to some formats. Each
+// is left at its zero value for other formats.
+type FormatSettings struct {
+	// HoldThirdPlaceMatch is true if a single elimination bracket has a
+	// match between the semi-final losers.
+	HoldThirdPlaceMatch bool
+	// GrandFinalsModifier is how a double elimination bracket's grand
+	// finals are played.
+	GrandFinalsModifier GrandFinalsModifier
+	// SwissRounds is the number of rounds in a swiss bracket.
+	SwissRounds int
+	// RoundRobinIterations is how many times each pair of players in a
+	// round robin pool meets.
+	RoundRobinIterations int
+}
+
+// roundRobinIterations works out how many times each pair of players meets
+// from the matches between them.
+func roundRobinIterations(matches []*Match) int {
+	meetings := make(map[[2]string]int)
+	iterations := 0
+	for _, m := range matches {
+		if !hasPlayer(m.Player1ID) || !hasPlayer(m.Player2ID) {
+			continue
+		}
+		pair := [2]string{m.Player1ID, m.Player2ID}
+		if pair[0] > pair[1] {
+			pair[0], pair[1] = pair[1], pair[0]
+		}
+		meetings[pair]++
+		if meetings[pair] > iterations {
+			iterations = meetings[pair]
+		}
+	}
+	return iterations
+}
+
+// maxRound returns the highest round of any match.
+func maxRound(matches []*Match) int {
+	rounds := 0
+	for _, m := range matches {
+		if m.Round > rounds {
+			rounds = m.Round
+		}
+	}
+	return rounds
+}
+
+func convertChallongeFormat(tournamentType string) BracketFormat {
+	switch tournamentType {
+	case "":
+		return FormatUnknown
+	case "single elimination":
+		return FormatSingleElimination
+	case "double elimination":
+		return FormatDoubleElimination
+	case "round robin":
+		return FormatRoundRobin
+	case "swiss":
+		return FormatSwiss
+	}
+	return FormatOther
+}
+
+// smash.gg phase group types.
+const (
+	smashGGGroupSingleElimination = 1
+	smashGGGroupDoubleElimination = 2
+	smashGGGroupRoundRobin        = 3
+	smashGGGroupSwiss             = 4
+)
+
+func convertSmashGGFormat(groupTypeID int) BracketFormat {
+	switch groupTypeID {
+	case 0:
+		return FormatUnknown
+	case smashGGGroupSingleElimination:
+		return FormatSingleElimination
+	case smashGGGroupDoubleElimination:
+		return FormatDoubleElimination
+	case smashGGGroupRoundRobin:
+		return FormatRoundRobin
+	case smashGGGroupSwiss:
+		return FormatSwiss
+	}
+	return FormatOther
+}
+
+func convertStartGGFormat(bracketType string) BracketFormat {
+	switch bracketType {
+	case "":
+		return FormatUnknown
+	case "SINGLE_ELIMINATION":
+		return FormatSingleElimination
+	case "DOUBLE_ELIMINATION":
+		return FormatDoubleElimination
+	case "ROUND_ROBIN":
+		return FormatRoundRobin
+	case "SWISS":
+		return FormatSwiss
+	}
+	return FormatOther
+}
+
+// smashGGFormatSettings works out the settings of a smash.gg or start.gg
+// bracket from its matches, since neither service returns them. The
+// matches' sides must already be assigned.
+func smashGGFormatSettings(format BracketFormat, matches []*Match) FormatSettings {
+	var s FormatSettings
+	switch format {
+	case FormatDoubleElimination:
+		s.GrandFinalsModifier = grandFinalsModifier(matches)
+	case FormatSwiss:
+		s.SwissRounds = maxRound(matches)
+	case FormatRoundRobin:
+		s.RoundRobinIterations = roundRobinIterations(matches)
+	}
+	return s
+}
+
+// grandFinalsModifier works out how a double elimination bracket's grand
+// finals are played from which grand final matches it has. Both services
+// create every match when the bracket starts, including a reset that may
+// never be played.
+func grandFinalsModifier(matches []*Match) GrandFinalsModifier {
+	if len(matches) == 0 {
+		return ""
+	}
+	modifier := GrandFinalsSkip
+	for _, m := range matches {
+		switch m.Side {
+		case MatchSideGrandFinalReset:
+			return GrandFinalsWithReset
+		case MatchSideGrandFinal:
+			modifier = GrandFinalsSingleMatch
+		}
+	}
+	return modifier
+}
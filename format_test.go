@@ -0,0 +1,105 @@
+package bracket
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChallongeFormat(t *testing.T) {
+	b, err := ioutil.ReadFile("testdata/challonge.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := decodeChallongeData(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bracket := convertChallongeData(resp, DefaultNameSeparators)
+	assert.Equal(t, FormatDoubleElimination, bracket.Format)
+	assert.Equal(t, FormatSettings{GrandFinalsModifier: GrandFinalsWithReset}, bracket.FormatSettings)
+}
+
+func TestConvertChallongeFormatSettings(t *testing.T) {
+	skip := "skip"
+	tests := []struct {
+		tournament *challongeTournament
+		want       FormatSettings
+	}{
+		{
+			&challongeTournament{TournamentType: "single elimination", HoldThirdPlaceMatch: true},
+			FormatSettings{HoldThirdPlaceMatch: true},
+		},
+		{
+			&challongeTournament{TournamentType: "double elimination", GrandFinalsModifier: &skip},
+			FormatSettings{GrandFinalsModifier: GrandFinalsSkip},
+		},
+		{
+			&challongeTournament{TournamentType: "swiss", SwissRounds: 5},
+			FormatSettings{SwissRounds: 5},
+		},
+		{
+			&challongeTournament{TournamentType: "round robin", Matches: []*challongeMatchWrap{
+				{&challongeMatch{ID: 1, Player1ID: 1, Player2ID: 2}},
+				{&challongeMatch{ID: 2, Player1ID: 2, Player2ID: 1}},
+				{&challongeMatch{ID: 3, Player1ID: 1, Player2ID: 3}},
+			}},
+			FormatSettings{RoundRobinIterations: 2},
+		},
+	}
+	for _, tt := range tests {
+		b := convertChallongeData(&challongeAPIResponse{tt.tournament}, DefaultNameSeparators)
+		assert.Equal(t, convertChallongeFormat(tt.tournament.TournamentType), b.Format)
+		assert.Equal(t, tt.want, b.FormatSettings, tt.tournament.TournamentType)
+	}
+}
+
+func TestConvertFormats(t *testing.T) {
+	assert.Equal(t, FormatUnknown, convertChallongeFormat(""))
+	assert.Equal(t, FormatOther, convertChallongeFormat("free for all"))
+	assert.Equal(t, FormatSingleElimination, convertSmashGGFormat(1))
+	assert.Equal(t, FormatDoubleElimination, convertSmashGGFormat(2))
+	assert.Equal(t, FormatRoundRobin, convertSmashGGFormat(3))
+	assert.Equal(t, FormatSwiss, convertSmashGGFormat(4))
+	assert.Equal(t, FormatOther, convertSmashGGFormat(5))
+	assert.Equal(t, FormatRoundRobin, convertStartGGFormat("ROUND_ROBIN"))
+	assert.Equal(t, FormatOther, convertStartGGFormat("EXHIBITION"))
+}
+
+func TestSmashGGFormat(t *testing.T) {
+	b, err := ioutil.ReadFile("testdata/smashgg_58playerbracket.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := decodeSmashGGData(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bracket := convertSmashGGData(resp)
+	assert.Equal(t, FormatDoubleElimination, bracket.Format)
+	assert.Equal(t, GrandFinalsWithReset, bracket.FormatSettings.GrandFinalsModifier)
+}
+
+func TestGrandFinalsModifier(t *testing.T) {
+	assert.Equal(t, GrandFinalsWithReset, grandFinalsModifier([]*Match{
+		{Side: MatchSideWinners}, {Side: MatchSideGrandFinal}, {Side: MatchSideGrandFinalReset},
+	}))
+	assert.Equal(t, GrandFinalsSingleMatch, grandFinalsModifier([]*Match{
+		{Side: MatchSideWinners}, {Side: MatchSideGrandFinal},
+	}))
+	assert.Equal(t, GrandFinalsSkip, grandFinalsModifier([]*Match{
+		{Side: MatchSideWinners}, {Side: MatchSideLosers},
+	}))
+	assert.Equal(t, GrandFinalsModifier(""), grandFinalsModifier(nil))
+}
+
+func TestSmashGGSwissRounds(t *testing.T) {
+	sets := []*smashGGSet{{ID: 1, Round: 1}, {ID: 2, Round: 2}, {ID: 3, Round: 3}}
+	bracket := convertSmashGGData(&smashGGAPIResponse{&smashGGEntities{
+		Groups: &smashGGGroup{GroupTypeID: 4},
+		Sets:   sets,
+	}})
+	assert.Equal(t, FormatSwiss, bracket.Format)
+	assert.Equal(t, 3, bracket.FormatSettings.SwissRounds)
+}
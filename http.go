@@ -0,0 +1,140 @@
+package bracket
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+const defaultUserAgent = "go-bracket"
+
+// apiRequest describes a call to a web service.
+type apiRequest struct {
+	// Provider is the name of the provider making the request.
+	Provider string
+	// Method defaults to GET.
+	Method string
+	URL    string
+	Body   []byte
+	// Idempotent marks a request that is safe to retry even though its
+	// method isn't, such as a GraphQL query sent with POST.
+	Idempotent bool
+	// Setup, if non-nil, can add authentication or other headers.
+	Setup func(*http.Request)
+	// DecodeErrors, if non-nil, pulls structured error messages out of
+	// an unsuccessful response body.
+	DecodeErrors func([]byte) []string
+}
+
+func (r *apiRequest) method() string {
+	if r.Method == "" {
+		return "GET"
+	}
+	return r.Method
+}
+
+// apiResponse is a successful (2xx or 304) response from a web service.
+type apiResponse struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// get makes a GET request and returns the response body, going through the
+// client's cache if it has one. Unsuccessful responses are returned as an
+// *APIError.
+func (c *Client) get(ctx context.Context, r *apiRequest) ([]byte, error) {
+	if c.cachePolicy.Cache != nil {
+		return c.getCached(ctx, r)
+	}
+	resp, err := c.do(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// do makes a request, retrying it according to the client's retry policy.
+// Every attempt waits on the provider's rate limiter, if it has one.
+func (c *Client) do(ctx context.Context, r *apiRequest) (*apiResponse, error) {
+	policy := c.retryPolicy
+	limiter := c.rateLimiters[r.Provider]
+	retryable := r.Idempotent || isRetryableMethod(r.method())
+	for attempt := 1; ; attempt++ {
+		if limiter != nil {
+			if err := limiter.wait(ctx); err != nil {
+				return nil, err
+			}
+		}
+		resp, header, err := c.doOnce(ctx, r)
+		if err == nil {
+			return resp, nil
+		}
+		if attempt >= policy.MaxAttempts || !retryable || !isRetryableError(ctx, err) {
+			return nil, err
+		}
+
+		delay := policy.retryDelay(attempt, header, time.Now())
+		if policy.OnRetry != nil {
+			statusCode := 0
+			var apiErr *APIError
+			if errors.As(err, &apiErr) {
+				statusCode = apiErr.StatusCode
+			}
+			policy.OnRetry(RetryAttempt{
+				Provider:   r.Provider,
+				URL:        r.URL,
+				Attempt:    attempt,
+				StatusCode: statusCode,
+				Err:        err,
+				Delay:      delay,
+			})
+		}
+		if err := sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// doOnce makes a single attempt at a request. The response headers are
+// returned even on failure so callers can inspect Retry-After.
+func (c *Client) doOnce(ctx context.Context, r *apiRequest) (*apiResponse, http.Header, error) {
+	var body io.Reader
+	if r.Body != nil {
+		body = bytes.NewReader(r.Body)
+	}
+	req, err := http.NewRequestWithContext(ctx, r.method(), r.URL, body)
+	if err != nil {
+		return nil, nil, err
+	}
+	req.Header.Set("User-Agent", c.userAgent)
+	if r.Setup != nil {
+		r.Setup(req)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, nil, &networkError{err}
+	}
+	defer resp.Body.Close()
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, resp.Header, &networkError{err}
+	}
+	// a 304 only makes sense in answer to a conditional request, which
+	// the cache makes when it has a response to fall back on
+	conditional := req.Header.Get("If-None-Match") != "" || req.Header.Get("If-Modified-Since") != ""
+	success := resp.StatusCode/100 == 2 || (resp.StatusCode == http.StatusNotModified && conditional)
+	if !success {
+		var messages []string
+		if r.DecodeErrors != nil {
+			messages = r.DecodeErrors(respBody)
+		}
+		return nil, resp.Header, newAPIError(r.Provider, resp.StatusCode, respBody, messages)
+	}
+	return &apiResponse{resp.StatusCode, resp.Header, respBody}, resp.Header, nil
+}
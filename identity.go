@@ -0,0 +1,369 @@
+package bracket
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+)
+
+// Confidence scores for identities that aren't backed by an account or
+// alias. A name can be shared by more than one person, so even an exact
+// match on it isn't certain.
+const (
+	NameMatchConfidence  = 0.9
+	DefaultMinConfidence = 0.75
+)
+
+// Aliases maps the names and accounts people have played under to the ID
+// of the person. Names are keyed by NormalizeName, and accounts by the
+// service and account ID, as in "@startgg:1092".
+type Aliases map[string]string
+
+// AliasError is returned by ParseAliases when a line of an alias file
+// can't be parsed.
+type AliasError struct {
+	// Line is the number of the line, starting at 1.
+	Line int
+	// Reason describes what was wrong with the line.
+	Reason string
+}
+
+func (e *AliasError) Error() string {
+	return fmt.Sprintf("bracket: invalid alias on line %d: %s", e.Line, e.Reason)
+}
+
+// ParseAliases parses an alias file. Each line gives a person's ID, then
+// the names and accounts they've played under, separated by commas:
+//
+//	dr-pizza = DPS|Dr. Pizza, Dr Pizza, @startgg:1092, @challonge:drpizza
+//
+// Blank lines and lines starting with # are ignored.
+func ParseAliases(r io.Reader) (Aliases, error) {
+	aliases := make(Aliases)
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fail := func(reason string) error {
+			return &AliasError{Line: line, Reason: reason}
+		}
+
+		i := strings.Index(text, "=")
+		if i < 0 {
+			return nil, fail("missing =")
+		}
+		id := strings.TrimSpace(text[:i])
+		if id == "" {
+			return nil, fail("missing person ID")
+		}
+		for _, alias := range strings.Split(text[i+1:], ",") {
+			key := aliasKey(strings.TrimSpace(alias))
+			if key == "" {
+				continue
+			}
+			if other, ok := aliases[key]; ok && other != id {
+				return nil, fail(fmt.Sprintf("%q is already an alias of %s", strings.TrimSpace(alias), other))
+			}
+			aliases[key] = id
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return aliases, nil
+}
+
+// LoadAliases reads and parses the alias file at path.
+func LoadAliases(path string) (Aliases, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ParseAliases(f)
+}
+
+func aliasKey(alias string) string {
+	if strings.HasPrefix(alias, "@") {
+		return strings.ToLower(alias)
+	}
+	return NormalizeName(alias)
+}
+
+// PlayerIdentity is a player in a bracket matched to the person they are.
+type PlayerIdentity struct {
+	Bracket *Bracket
+	Player  *Player
+	// PersonID is the same for every player resolved to the same person.
+	PersonID string
+	// Confidence is how sure the match is, from 0 to 1. Players matched by
+	// account or alias, and players seen for the first time, have a
+	// confidence of 1.
+	Confidence float64
+}
+
+// IdentityResolver works out which players in a set of brackets are the
+// same person.
+type IdentityResolver struct {
+	// Aliases are names and accounts known to belong to a person.
+	Aliases Aliases
+	// MinConfidence is the lowest confidence a name match may have before
+	// the player is treated as a new person. Zero means
+	// DefaultMinConfidence.
+	MinConfidence float64
+}
+
+type person struct {
+	id       string
+	accounts map[string]bool
+}
+
+// Resolve matches every player in the brackets to a person, in the order
+// the brackets and their players are given. Teams are resolved member by
+// member.
+//
+// A player is matched by their service account first, then by the alias
+// file, then by their normalized name. Names that don't match exactly are
+// compared by edit distance, scaled down from NameMatchConfidence. Two
+// players with different accounts on the same service are never the same
+// person.
+//
+// People found in the alias file have the ID given there. Other people
+// are identified by the first player resolved to them: by their account,
+// such as "@startgg:1092", or failing that by their normalized name, such
+// as "name:drpizza". Players whose names normalize to nothing are
+// identified by the bracket's URL and their ID, as in
+// "player:http://challonge.com/mra2#1".
+//
+// Since each player is only compared with the players resolved before
+// them, the result depends on the order of the brackets. If "Lefen" comes
+// first, "Leffen" merges into the person identified as "name:lefen"; in
+// the other order the ID is "name:leffen".
+func (r *IdentityResolver) Resolve(brackets ...*Bracket) []*PlayerIdentity {
+	minConfidence := r.MinConfidence
+	if minConfidence == 0 {
+		minConfidence = DefaultMinConfidence
+	}
+
+	people := make(map[string]*person)
+	byAccount := make(map[string]*person)
+	names := newNameIndex(minConfidence)
+
+	var identities []*PlayerIdentity
+	for _, b := range brackets {
+		service := accountService(b.URL)
+		for _, p := range b.Players {
+			players := []*Player{p}
+			if len(p.Members) > 0 {
+				players = p.Members
+			}
+			for _, player := range players {
+				account := ""
+				if player.AccountID != "" && service != "" {
+					account = "@" + service + ":" + strings.ToLower(player.AccountID)
+				}
+				name := NormalizeName(player.Name)
+				if player.GamerTag != "" {
+					name = NormalizeName(player.GamerTag)
+				}
+
+				var match *person
+				confidence := 1.0
+				switch {
+				case account != "" && r.Aliases[account] != "":
+					match = lookupPerson(people, r.Aliases[account])
+				case account != "" && byAccount[account] != nil:
+					match = byAccount[account]
+				case name != "" && r.Aliases[name] != "" &&
+					(account == "" || !hasOtherAccount(people[r.Aliases[name]], account)):
+					// an aliased name doesn't take in a player whose
+					// account says they're someone else
+					match = lookupPerson(people, r.Aliases[name])
+				case name != "":
+					match, confidence = names.match(name, account)
+					if confidence < minConfidence {
+						match, confidence = nil, 1
+					}
+				}
+
+				if match == nil {
+					id := account
+					if id == "" && name != "" {
+						id = "name:" + name
+					}
+					if id == "" {
+						id = "player:" + b.URL + "#" + player.ID
+					}
+					match = lookupPerson(people, id)
+				}
+				if account != "" {
+					match.accounts[account] = true
+					byAccount[account] = match
+				}
+				if name != "" {
+					names.add(name, match)
+				}
+
+				identities = append(identities, &PlayerIdentity{
+					Bracket:    b,
+					Player:     player,
+					PersonID:   match.id,
+					Confidence: confidence,
+				})
+			}
+		}
+	}
+	return identities
+}
+
+func lookupPerson(people map[string]*person, id string) *person {
+	p, ok := people[id]
+	if !ok {
+		p = &person{id: id, accounts: make(map[string]bool)}
+		people[id] = p
+	}
+	return p
+}
+
+// nameIndex holds the names seen so far, grouped by length so that a name
+// is only compared with names short or long enough to be a close match.
+type nameIndex struct {
+	// minRatio is the shortest a name can be, relative to a longer one,
+	// and still be a close enough match. Names differ by at least the
+	// difference in their lengths.
+	minRatio float64
+	longest  int
+	byName   map[string]*person
+	byLength map[int][]string
+	// order is the position of each name in the order they were added,
+	// which decides between equally close matches.
+	order map[string]int
+}
+
+func newNameIndex(minConfidence float64) *nameIndex {
+	return &nameIndex{
+		minRatio: minConfidence / NameMatchConfidence,
+		byName:   make(map[string]*person),
+		byLength: make(map[int][]string),
+		order:    make(map[string]int),
+	}
+}
+
+// add records that name belongs to p, unless it already belongs to
+// someone.
+func (x *nameIndex) add(name string, p *person) {
+	if x.byName[name] != nil {
+		return
+	}
+	x.byName[name] = p
+	n := len([]rune(name))
+	x.byLength[n] = append(x.byLength[n], name)
+	if n > x.longest {
+		x.longest = n
+	}
+	x.order[name] = len(x.order)
+}
+
+// match finds the person whose name is closest to name, skipping anyone
+// with a different account on the same service.
+func (x *nameIndex) match(name, account string) (*person, float64) {
+	n := len([]rune(name))
+	minLength, maxLength := 0, x.longest
+	if x.minRatio > 0 {
+		// allow for rounding when a length is right on the limit
+		const slack = 1e-9
+		minLength = int(math.Ceil(float64(n)*x.minRatio - slack))
+		if l := int(float64(n)/x.minRatio + slack); l < maxLength {
+			maxLength = l
+		}
+	}
+
+	var best *person
+	bestScore, bestOrder := 0.0, 0
+	for length := minLength; length <= maxLength; length++ {
+		for _, other := range x.byLength[length] {
+			p := x.byName[other]
+			if account != "" && hasOtherAccount(p, account) {
+				continue
+			}
+			score := nameSimilarity(other, name) * NameMatchConfidence
+			if score > bestScore || (score == bestScore && best != nil && x.order[other] < bestOrder) {
+				best, bestScore, bestOrder = p, score, x.order[other]
+			}
+		}
+	}
+	return best, bestScore
+}
+
+// hasOtherAccount reports whether p has an account on the same service as
+// account, other than account itself. A nil p is someone not seen yet.
+func hasOtherAccount(p *person, account string) bool {
+	if p == nil {
+		return false
+	}
+	service := account[:strings.Index(account, ":")+1]
+	for a := range p.accounts {
+		if a != account && strings.HasPrefix(a, service) {
+			return true
+		}
+	}
+	return false
+}
+
+// accountService names the service whose account IDs a bracket's players
+// have. smash.gg and start.gg share accounts.
+func accountService(url string) string {
+	switch {
+	case isChallongeURL(url):
+		return "challonge"
+	case isStartGGURL(url), isSmashGGURL(url):
+		return "startgg"
+	}
+	return ""
+}
+
+// nameSimilarity scores how alike two names are from 0 to 1, by their
+// edit distance relative to the longer name.
+func nameSimilarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(editDistance(ra, rb))/float64(longest)
+}
+
+// editDistance is the Levenshtein distance between a and b.
+func editDistance(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = prev[j-1] + cost
+			if prev[j]+1 < cur[j] {
+				cur[j] = prev[j] + 1
+			}
+			if cur[j-1]+1 < cur[j] {
+				cur[j] = cur[j-1] + 1
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,164 @@
+package bracket
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAliases(t *testing.T) {
+	aliases, err := ParseAliases(strings.NewReader(`
+# Missouri regulars
+dr-pizza = DPS|Dr. Pizza, Pizza, @startgg:1092
+hite = YCL|Hite, @Challonge:Hite
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, Aliases{
+		"drpizza":         "dr-pizza",
+		"pizza":           "dr-pizza",
+		"@startgg:1092":   "dr-pizza",
+		"hite":            "hite",
+		"@challonge:hite": "hite",
+	}, aliases)
+
+	_, err = ParseAliases(strings.NewReader("dr-pizza: Dr. Pizza"))
+	var aliasErr *AliasError
+	assert.True(t, errors.As(err, &aliasErr))
+	assert.Equal(t, 1, aliasErr.Line)
+
+	_, err = ParseAliases(strings.NewReader("dr-pizza = Dr. Pizza\n\nother = DPS|Dr Pizza"))
+	assert.EqualError(t, err, `bracket: invalid alias on line 3: "DPS|Dr Pizza" is already an alias of dr-pizza`)
+}
+
+func resolvedIDs(identities []*PlayerIdentity) []string {
+	ids := make([]string, len(identities))
+	for i, id := range identities {
+		ids[i] = id.PersonID
+	}
+	return ids
+}
+
+func TestResolveIdentities(t *testing.T) {
+	challonge := &Bracket{URL: "http://challonge.com/mra2", Players: []*Player{
+		{ID: "1", Name: "(P1W) DPS|Dr. Pizza"},
+		{ID: "2", Name: "(P1L) YCL|Hite"},
+	}}
+	startgg := &Bracket{URL: "https://www.start.gg/tournament/x/event/y/brackets/1/2", Players: []*Player{
+		{ID: "10", Name: "Dr Piza", GamerTag: "Dr Piza", AccountID: "1092"},
+		{ID: "11", Name: "Hite", GamerTag: "Hite", AccountID: "2000"},
+		{ID: "12", Name: "Hite", GamerTag: "Hite", AccountID: "3000"},
+	}}
+	smashgg := &Bracket{URL: "https://smash.gg/tournament/z/brackets/1/2/3", Players: []*Player{
+		{ID: "20", Name: "DPS | Doctor", GamerTag: "Doctor", AccountID: "1092"},
+		{ID: "21", Name: "Bob"},
+	}}
+
+	var r IdentityResolver
+	identities := r.Resolve(challonge, startgg, smashgg)
+	assert.Equal(t, []string{"name:drpizza", "name:hite", "name:drpizza", "name:hite", "@startgg:3000", "name:drpizza", "name:bob"}, resolvedIDs(identities))
+	assert.Equal(t, startgg.Players[0], identities[2].Player)
+	assert.Equal(t, startgg, identities[2].Bracket)
+
+	// first appearances are certain
+	assert.Equal(t, 1.0, identities[0].Confidence)
+	// "drpiza" is one edit from "drpizza"
+	assert.InDelta(t, NameMatchConfidence*6/7, identities[2].Confidence, 0.001)
+	assert.Equal(t, NameMatchConfidence, identities[3].Confidence)
+	// a second account on the same service is a different person
+	assert.Equal(t, 1.0, identities[4].Confidence)
+	// matched by account despite the different name
+	assert.Equal(t, 1.0, identities[5].Confidence)
+
+	// with a stricter threshold, the misspelling is a new person
+	r.MinConfidence = 0.8
+	identities = r.Resolve(challonge, startgg)
+	assert.Equal(t, "@startgg:1092", identities[2].PersonID)
+}
+
+func TestResolveIdentitiesWithAliases(t *testing.T) {
+	aliases, err := ParseAliases(strings.NewReader("dr-pizza = Pizza, @startgg:1092\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := IdentityResolver{Aliases: aliases}
+	identities := r.Resolve(
+		&Bracket{URL: "http://challonge.com/a", Players: []*Player{{ID: "1", Name: "(P1W) DPS|Pizza"}}},
+		&Bracket{URL: "https://www.start.gg/b", Players: []*Player{
+			{ID: "2", Name: "Dr. Pizza", AccountID: "1092"},
+			{ID: "3", Name: "DPS / CDK", Members: []*Player{
+				{ID: "4", Name: "Pizza", GamerTag: "Pizza"},
+				{ID: "5", Name: "CDK", GamerTag: "CDK"},
+			}},
+		}},
+	)
+	assert.Equal(t, []string{"dr-pizza", "dr-pizza", "dr-pizza", "name:cdk"}, resolvedIDs(identities))
+	for _, id := range identities {
+		assert.Equal(t, 1.0, id.Confidence)
+	}
+}
+
+func TestResolveIdentitiesAliasedNameWithOtherAccount(t *testing.T) {
+	aliases, err := ParseAliases(strings.NewReader("dr-pizza = Pizza, @startgg:1092\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := IdentityResolver{Aliases: aliases}
+	identities := r.Resolve(
+		&Bracket{URL: "https://www.start.gg/a", Players: []*Player{{ID: "1", Name: "Pizza", AccountID: "1092"}}},
+		&Bracket{URL: "https://www.start.gg/b", Players: []*Player{{ID: "2", Name: "Pizza", AccountID: "555"}}},
+	)
+	// a second start.gg account can't be the aliased person, even with the
+	// same name
+	assert.Equal(t, []string{"dr-pizza", "@startgg:555"}, resolvedIDs(identities))
+}
+
+func TestResolveIdentitiesKeepsIDsApart(t *testing.T) {
+	aliases, err := ParseAliases(strings.NewReader("bob = Robert\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := IdentityResolver{Aliases: aliases}
+	identities := r.Resolve(
+		&Bracket{URL: "http://challonge.com/a", Players: []*Player{
+			{ID: "1", Name: "Robert"},
+			{ID: "2", Name: "Bob"},
+			{ID: "3", Name: "★★"},
+		}},
+		&Bracket{URL: "http://challonge.com/b", Players: []*Player{
+			{ID: "3", Name: "!!!"},
+		}},
+	)
+	// the alias ID "bob" isn't the same person as an unaliased "Bob", and
+	// players with no usable name aren't matched across brackets by ID
+	assert.Equal(t, []string{"bob", "name:bob", "player:http://challonge.com/a#3", "player:http://challonge.com/b#3"}, resolvedIDs(identities))
+}
+
+func TestResolveIdentitiesDependsOnOrder(t *testing.T) {
+	lefen := &Bracket{URL: "http://challonge.com/a", Players: []*Player{{ID: "1", Name: "Lefen"}}}
+	leffen := &Bracket{URL: "http://challonge.com/b", Players: []*Player{{ID: "1", Name: "Leffen"}}}
+
+	var r IdentityResolver
+	// one edit in six is just close enough
+	assert.Equal(t, []string{"name:lefen", "name:lefen"}, resolvedIDs(r.Resolve(lefen, leffen)))
+	assert.Equal(t, []string{"name:leffen", "name:leffen"}, resolvedIDs(r.Resolve(leffen, lefen)))
+}
+
+func TestNameIndexComparesCloseLengths(t *testing.T) {
+	x := newNameIndex(DefaultMinConfidence)
+	a, b := &person{id: "a"}, &person{id: "b"}
+	x.add("ab", a)
+	x.add("abcdefghij", b)
+	p, confidence := x.match("abcdefghi", "")
+	assert.Equal(t, b, p)
+	assert.InDelta(t, NameMatchConfidence*0.9, confidence, 0.001)
+
+	// "ab" is too short to ever be close enough to "abx", so the two
+	// aren't compared at all
+	p, confidence = x.match("abx", "")
+	assert.Nil(t, p)
+	assert.Equal(t, 0.0, confidence)
+}
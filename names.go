@@ -0,0 +1,110 @@
+package bracket
+
+import (
+	"strings"
+	"unicode"
+)
+
+// DefaultNameSeparators are the separators most often used between a
+// sponsor prefix and a gamer tag.
+var DefaultNameSeparators = []string{"|", "｜", "丨"}
+
+// sponsoredName joins a sponsor prefix and gamer tag the way start.gg
+// displays them, such as "TA | CDK".
+func sponsoredName(prefix, gamerTag string) string {
+	if prefix == "" {
+		return gamerTag
+	}
+	return prefix + " | " + gamerTag
+}
+
+// SplitSponsor splits a display name such as "DPS | Dr. Pizza" into a
+// sponsor prefix and gamer tag at the last of any of the separators. A name
+// without a separator is all gamer tag.
+func SplitSponsor(name string, separators []string) (prefix, gamerTag string) {
+	i, n := -1, 0
+	for _, sep := range separators {
+		if sep == "" {
+			continue
+		}
+		if j := strings.LastIndex(name, sep); j > i {
+			i, n = j, len(sep)
+		}
+	}
+	if i < 0 {
+		return "", strings.TrimSpace(name)
+	}
+	prefix = strings.TrimSpace(name[:i])
+	gamerTag = strings.TrimSpace(name[i+n:])
+	if gamerTag == "" {
+		return "", strings.TrimSpace(name)
+	}
+	return prefix, gamerTag
+}
+
+// stripAnnotations removes anything in round, square or curly brackets,
+// such as the "(P1W)" organizers add to mark a player's pool. A name that
+// is all annotation is returned as it is.
+func stripAnnotations(name string) string {
+	var b strings.Builder
+	depth := 0
+	for _, r := range name {
+		switch r {
+		case '(', '[', '{', '（', '［':
+			depth++
+		case ')', ']', '}', '）', '］':
+			if depth > 0 {
+				depth--
+			}
+		default:
+			if depth == 0 {
+				b.WriteRune(r)
+			}
+		}
+	}
+	if strings.TrimSpace(b.String()) == "" {
+		return name
+	}
+	return b.String()
+}
+
+// confusables maps characters that look like ASCII letters, such as
+// Cyrillic and Greek lookalikes and accented Latin letters, to the letter
+// they're mistaken for. Fullwidth forms are handled separately.
+var confusables = map[rune]rune{
+	'а': 'a', 'в': 'b', 'е': 'e', 'ё': 'e', 'к': 'k', 'м': 'm', 'н': 'h',
+	'о': 'o', 'р': 'p', 'с': 'c', 'т': 't', 'у': 'y', 'х': 'x', 'і': 'i',
+	'ї': 'i', 'ј': 'j', 'ѕ': 's', 'ԁ': 'd', 'ԛ': 'q', 'ԝ': 'w',
+	'α': 'a', 'β': 'b', 'ε': 'e', 'η': 'n', 'ι': 'i', 'κ': 'k', 'ν': 'v',
+	'ο': 'o', 'ρ': 'p', 'τ': 't', 'υ': 'u', 'χ': 'x',
+	'à': 'a', 'á': 'a', 'â': 'a', 'ã': 'a', 'ä': 'a', 'å': 'a', 'ā': 'a',
+	'ç': 'c', 'è': 'e', 'é': 'e', 'ê': 'e', 'ë': 'e', 'ē': 'e',
+	'ì': 'i', 'í': 'i', 'î': 'i', 'ï': 'i', 'ī': 'i', 'ı': 'i',
+	'ñ': 'n', 'ò': 'o', 'ó': 'o', 'ô': 'o', 'õ': 'o', 'ö': 'o', 'ø': 'o', 'ō': 'o',
+	'ù': 'u', 'ú': 'u', 'û': 'u', 'ü': 'u', 'ū': 'u', 'ý': 'y', 'ÿ': 'y',
+	'ß': 's', 'ł': 'l', 'ś': 's', 'š': 's', 'ž': 'z', 'ź': 'z', 'ż': 'z',
+}
+
+// NormalizeName reduces a display name to the part that identifies the
+// person, so that names such as "(P1W) DPS|Dr. Pizza" and "dr pizza" compare
+// equal. Annotations in brackets and the sponsor prefix are removed, case
+// is folded, lookalike characters are replaced with the letter they look
+// like, and everything but letters and digits is dropped.
+func NormalizeName(name string) string {
+	_, gamerTag := SplitSponsor(stripAnnotations(name), DefaultNameSeparators)
+
+	var b strings.Builder
+	for _, r := range strings.ToLower(gamerTag) {
+		if r >= '！' && r <= '～' {
+			// fullwidth forms of ASCII
+			r -= '！' - '!'
+		}
+		if c, ok := confusables[r]; ok {
+			r = c
+		}
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return b.String()
+}
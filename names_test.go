@@ -0,0 +1,57 @@
+package bracket
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitSponsor(t *testing.T) {
+	tests := []struct {
+		name, prefix, gamerTag string
+	}{
+		{"DPS|Dr. Pizza", "DPS", "Dr. Pizza"},
+		{"TA | CDK", "TA", "CDK"},
+		{"C9 ｜ Mang0", "C9", "Mang0"},
+		{"TSM | C9 | Leffen", "TSM | C9", "Leffen"},
+		{"  Slime ", "", "Slime"},
+		{"Trailing|", "", "Trailing|"},
+	}
+	for _, tt := range tests {
+		prefix, gamerTag := SplitSponsor(tt.name, DefaultNameSeparators)
+		assert.Equal(t, tt.prefix, prefix, tt.name)
+		assert.Equal(t, tt.gamerTag, gamerTag, tt.name)
+	}
+
+	prefix, gamerTag := SplitSponsor("DPS - Dr. Pizza", []string{" - "})
+	assert.Equal(t, "DPS", prefix)
+	assert.Equal(t, "Dr. Pizza", gamerTag)
+
+	prefix, gamerTag = SplitSponsor("DPS|Dr. Pizza", nil)
+	assert.Equal(t, "", prefix)
+	assert.Equal(t, "DPS|Dr. Pizza", gamerTag)
+}
+
+func TestStripAnnotations(t *testing.T) {
+	assert.Equal(t, " DPS|Dr. Pizza", stripAnnotations("(P1W) DPS|Dr. Pizza"))
+	assert.Equal(t, "Mango  ", stripAnnotations("Mango [L] {seed 3}"))
+	assert.Equal(t, "(TBD)", stripAnnotations("(TBD)"))
+}
+
+func TestNormalizeName(t *testing.T) {
+	tests := []struct {
+		name, normalized string
+	}{
+		{"(P1W) DPS|Dr. Pizza", "drpizza"},
+		{"DPS|Dr. Pizza", "drpizza"},
+		{"Dr Pizza [WR]", "drpizza"},
+		{"ＤＲ．ＰＩＺＺＡ", "drpizza"},
+		{"Dr. Рizzа", "drpizza"}, // Cyrillic Р and а
+		{"Hungrybox", "hungrybox"},
+		{"Mañgo", "mango"},
+		{"(P1L)", "p1l"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.normalized, NormalizeName(tt.name), tt.name)
+	}
+}
@@ -0,0 +1,91 @@
+package bracket
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Option configures a Client created with New.
+type Option func(*Client)
+
+// WithHTTPClient sets the HTTP client used to call the web services.
+// By default, http.DefaultClient is used.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = hc
+	}
+}
+
+// WithTransport sets the RoundTripper used to call the web services,
+// keeping any other settings of the HTTP client.
+func WithTransport(rt http.RoundTripper) Option {
+	return func(c *Client) {
+		hc := *c.httpClient
+		hc.Transport = rt
+		c.httpClient = &hc
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with every request.
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
+}
+
+// WithChallongeCredentials sets the username and API key used to
+// authenticate with Challonge.
+func WithChallongeCredentials(user, apiKey string) Option {
+	return func(c *Client) {
+		c.challongeUser = user
+		c.challongeAPIKey = apiKey
+	}
+}
+
+// WithChallongeBaseURL overrides the base URL of the Challonge API,
+// e.g. to point the client at a local stand-in.
+func WithChallongeBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		c.challongeBaseURL = withTrailingSlash(baseURL)
+	}
+}
+
+// WithChallongeNameSeparators sets the separators that split a sponsor
+// prefix from a gamer tag in Challonge display names, in place of
+// DefaultNameSeparators. With no separators, names aren't split.
+func WithChallongeNameSeparators(separators ...string) Option {
+	return func(c *Client) {
+		c.challongeNameSeparators = append([]string(nil), separators...)
+	}
+}
+
+// WithSmashGGBaseURL overrides the base URL of the Smash.GG API,
+// e.g. to point the client at a local stand-in.
+func WithSmashGGBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		c.smashGGBaseURL = withTrailingSlash(baseURL)
+	}
+}
+
+// WithStartGGToken sets the API token used to authenticate with start.gg.
+// Once set, smash.gg URLs are also fetched through the start.gg API.
+func WithStartGGToken(token string) Option {
+	return func(c *Client) {
+		c.startGGToken = token
+	}
+}
+
+// WithStartGGEndpoint overrides the URL of the start.gg GraphQL API,
+// e.g. to point the client at a local stand-in.
+func WithStartGGEndpoint(endpoint string) Option {
+	return func(c *Client) {
+		c.startGGEndpoint = endpoint
+	}
+}
+
+func withTrailingSlash(url string) string {
+	if strings.HasSuffix(url, "/") {
+		return url
+	}
+	return url + "/"
+}
@@ -0,0 +1,85 @@
+package bracket
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewDefaults(t *testing.T) {
+	c := New()
+	assert.Equal(t, http.DefaultClient, c.httpClient)
+	assert.Equal(t, defaultUserAgent, c.userAgent)
+	assert.Equal(t, defaultChallongeBaseURL, c.challongeBaseURL)
+	assert.Equal(t, defaultSmashGGBaseURL, c.smashGGBaseURL)
+}
+
+func TestChallongeNameSeparators(t *testing.T) {
+	assert.Equal(t, DefaultNameSeparators, New().challongeNameSeparators)
+	assert.Equal(t, []string{" - "}, New(WithChallongeNameSeparators(" - ")).challongeNameSeparators)
+	assert.Empty(t, New(WithChallongeNameSeparators()).challongeNameSeparators)
+
+	// changing a client's separators doesn't change the defaults
+	c := New()
+	c.challongeNameSeparators[0] = " - "
+	assert.Equal(t, "|", DefaultNameSeparators[0])
+}
+
+func TestNewClientShim(t *testing.T) {
+	c := NewClient("user", "key")
+	assert.Equal(t, "user", c.challongeUser)
+	assert.Equal(t, "key", c.challongeAPIKey)
+}
+
+func TestWithTransportKeepsClientSettings(t *testing.T) {
+	hc := &http.Client{Timeout: 5}
+	rt := &http.Transport{}
+	c := New(WithHTTPClient(hc), WithTransport(rt))
+	assert.Equal(t, rt, c.httpClient.Transport)
+	assert.EqualValues(t, 5, c.httpClient.Timeout)
+	// The caller's client is left untouched
+	assert.Nil(t, hc.Transport)
+}
+
+func TestChallongeBaseURL(t *testing.T) {
+	fixture, err := ioutil.ReadFile("testdata/challonge.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, key, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "user", user)
+		assert.Equal(t, "key", key)
+		assert.Equal(t, "test-agent", r.UserAgent())
+		assert.Equal(t, "/v1/tournaments/xyfuz5c3.json", r.URL.Path)
+		w.Write(fixture)
+	}))
+	defer server.Close()
+
+	c := New(
+		WithChallongeCredentials("user", "key"),
+		WithChallongeBaseURL(server.URL+"/v1"),
+		WithHTTPClient(server.Client()),
+		WithUserAgent("test-agent"),
+	)
+	b, err := c.FetchBracket("http://challonge.com/xyfuz5c3")
+	assert.NoError(t, err)
+	assert.Equal(t, "Missouri River Arcadian - The Sequel: Smash4 Top 16", b.Name)
+}
+
+func TestSmashGGBaseURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/phase_group/165583;expand=[\"sets\",\"seeds\",\"standings\"];mutations=[\"playerData\"];reset=false", r.URL.Path)
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	c := New(WithSmashGGBaseURL(server.URL))
+	_, err := c.FetchBracket("https://smash.gg/tournament/super-smash-sundays-48/brackets/14221/50133/165583")
+	assert.True(t, errors.Is(err, ErrNotFound))
+}
@@ -0,0 +1,68 @@
+package bracket
+
+// MatchOutcome describes how a completed match was decided.
+type MatchOutcome int
+
+const (
+	// OutcomeUndecided means the match hasn't been decided yet.
+	OutcomeUndecided MatchOutcome = iota
+	// OutcomePlayed means the match was played out.
+	OutcomePlayed
+	// OutcomeDQ means the loser was disqualified.
+	OutcomeDQ
+	// OutcomeForfeit means the loser withdrew from the tournament.
+	OutcomeForfeit
+	// OutcomeBye means the match has only one player by design, who
+	// advances without playing.
+	OutcomeBye
+	// OutcomeWalkover means a winner was reported without any scores,
+	// usually because the other player didn't show up.
+	OutcomeWalkover
+)
+
+func (o MatchOutcome) String() string {
+	switch o {
+	case OutcomeUndecided:
+		return "undecided"
+	case OutcomePlayed:
+		return "played"
+	case OutcomeDQ:
+		return "DQ"
+	case OutcomeForfeit:
+		return "forfeit"
+	case OutcomeBye:
+		return "bye"
+	case OutcomeWalkover:
+		return "walkover"
+	}
+	return "unknown"
+}
+
+// scoredOutcome works out the outcome of a match from its state and scores,
+// using the conventions shared by every provider: a negative score marks a
+// disqualification, and a winner with no score a walkover.
+func scoredOutcome(m *Match) MatchOutcome {
+	if m.State != MatchStateComplete || !hasPlayer(m.WinnerID) {
+		return OutcomeUndecided
+	}
+	if m.Player1Score < 0 || m.Player2Score < 0 {
+		return OutcomeDQ
+	}
+	if m.Player1Score == 0 && m.Player2Score == 0 {
+		return OutcomeWalkover
+	}
+	return OutcomePlayed
+}
+
+// PlayedMatches returns the matches in the bracket that were played out,
+// leaving out byes, disqualifications, forfeits, walkovers and matches
+// that haven't finished.
+func (b *Bracket) PlayedMatches() []*Match {
+	var matches []*Match
+	for _, m := range b.Matches {
+		if m.Outcome == OutcomePlayed {
+			matches = append(matches, m)
+		}
+	}
+	return matches
+}
@@ -0,0 +1,50 @@
+package bracket
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScoredOutcome(t *testing.T) {
+	tests := []struct {
+		match *Match
+		want  MatchOutcome
+	}{
+		{&Match{State: MatchStateOpen, WinnerID: "0"}, OutcomeUndecided},
+		{&Match{State: MatchStateComplete, WinnerID: "0"}, OutcomeUndecided},
+		{&Match{State: MatchStateComplete, WinnerID: "1", Player1Score: 2, Player2Score: 1}, OutcomePlayed},
+		{&Match{State: MatchStateComplete, WinnerID: "1", Player1Score: 0, Player2Score: -1}, OutcomeDQ},
+		{&Match{State: MatchStateComplete, WinnerID: "1"}, OutcomeWalkover},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, scoredOutcome(tt.match), "%+v", tt.match)
+	}
+}
+
+func TestPlayedMatches(t *testing.T) {
+	played := &Match{ID: "1", Outcome: OutcomePlayed}
+	b := &Bracket{Matches: []*Match{
+		played,
+		{ID: "2", Outcome: OutcomeBye},
+		{ID: "3", Outcome: OutcomeDQ},
+		{ID: "4", Outcome: OutcomeUndecided},
+	}}
+	assert.Equal(t, []*Match{played}, b.PlayedMatches())
+}
+
+func TestChallongeForfeit(t *testing.T) {
+	forfeited := true
+	matches := convertChallongeMatches([]*challongeMatchWrap{
+		{&challongeMatch{ID: 1, State: "complete", Player1ID: 1, Player2ID: 2, WinnerID: 1, LoserID: 2, Forfeited: &forfeited}},
+		{&challongeMatch{ID: 2, State: "open", Player1ID: 1, Player2ID: 2, Forfeited: &forfeited}},
+	})
+	assert.Equal(t, OutcomeForfeit, matches[0].Outcome)
+	assert.Equal(t, OutcomeUndecided, matches[1].Outcome)
+}
+
+func TestMatchOutcomeString(t *testing.T) {
+	assert.Equal(t, "DQ", OutcomeDQ.String())
+	assert.Equal(t, "bye", OutcomeBye.String())
+	assert.Equal(t, "unknown", MatchOutcome(99).String())
+}
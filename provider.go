@@ -0,0 +1,73 @@
+package bracket
+
+import (
+	"context"
+	"sync"
+)
+
+// Provider fetches brackets from a single bracket service. Implementations
+// can be registered on a Client to add support for services that aren't
+// built in.
+type Provider interface {
+	// Name returns a short name identifying the service, e.g. "challonge".
+	Name() string
+	// MatchURL reports whether the provider can fetch the given web URL.
+	MatchURL(url string) bool
+	// FetchBracket fetches the bracket at the given web URL. Implementations
+	// should abandon any in-flight requests once ctx is done.
+	FetchBracket(ctx context.Context, url string) (*Bracket, error)
+}
+
+type registeredProvider struct {
+	provider Provider
+	priority int
+}
+
+type providerRegistry struct {
+	mu        sync.RWMutex
+	providers []registeredProvider
+}
+
+// register inserts p so that providers stay ordered by descending
+// priority, with newer registrations ahead of older ones of equal priority.
+func (r *providerRegistry) register(p Provider, priority int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	i := 0
+	for i < len(r.providers) && r.providers[i].priority > priority {
+		i++
+	}
+	r.providers = append(r.providers, registeredProvider{})
+	copy(r.providers[i+1:], r.providers[i:])
+	r.providers[i] = registeredProvider{p, priority}
+}
+
+func (r *providerRegistry) lookup(url string) Provider {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, rp := range r.providers {
+		if rp.provider.MatchURL(url) {
+			return rp.provider
+		}
+	}
+	return nil
+}
+
+// RegisterProvider adds a provider to the client.
+//
+// When more than one provider matches a URL, the provider with the highest
+// priority is used. Among providers with equal priority, the most recently
+// registered one wins. The built-in providers are registered with priority 0,
+// so registering a provider with priority 0 or higher overrides them for any
+// URL it matches.
+func (c *Client) RegisterProvider(p Provider, priority int) {
+	c.registry.register(p, priority)
+}
+
+// ProviderFor returns the provider that would be used to fetch the given
+// URL, or nil if no registered provider matches it.
+func (c *Client) ProviderFor(url string) Provider {
+	return c.registry.lookup(url)
+}
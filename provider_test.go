@@ -0,0 +1,63 @@
+package bracket
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeProvider struct {
+	name   string
+	prefix string
+}
+
+func (p *fakeProvider) Name() string {
+	return p.name
+}
+
+func (p *fakeProvider) MatchURL(url string) bool {
+	return strings.HasPrefix(url, p.prefix)
+}
+
+func (p *fakeProvider) FetchBracket(ctx context.Context, url string) (*Bracket, error) {
+	return &Bracket{Name: p.name, URL: url}, nil
+}
+
+func TestBuiltInProviders(t *testing.T) {
+	c := NewClient("", "")
+	assert.Equal(t, "challonge", c.ProviderFor("http://challonge.com/xyfuz5c3").Name())
+	assert.Equal(t, "smashgg", c.ProviderFor("https://smash.gg/tournament/super-smash-sundays-48/brackets/14221/50133/165583").Name())
+	assert.Nil(t, c.ProviderFor("https://example.com/bracket"))
+}
+
+func TestRegisterProvider(t *testing.T) {
+	c := NewClient("", "")
+	c.RegisterProvider(&fakeProvider{"example", "https://example.com/"}, 0)
+
+	b, err := c.FetchBracket("https://example.com/bracket")
+	assert.NoError(t, err)
+	assert.Equal(t, "example", b.Name)
+	assert.Equal(t, "https://example.com/bracket", b.URL)
+}
+
+func TestProviderPriority(t *testing.T) {
+	c := NewClient("", "")
+	url := "http://challonge.com/xyfuz5c3"
+
+	// Lower priority never beats a built-in
+	c.RegisterProvider(&fakeProvider{"low", "http://challonge.com/"}, -1)
+	assert.Equal(t, "challonge", c.ProviderFor(url).Name())
+
+	// Equal priority: most recent registration wins
+	c.RegisterProvider(&fakeProvider{"first", "http://challonge.com/"}, 0)
+	assert.Equal(t, "first", c.ProviderFor(url).Name())
+	c.RegisterProvider(&fakeProvider{"second", "http://challonge.com/"}, 0)
+	assert.Equal(t, "second", c.ProviderFor(url).Name())
+
+	// Higher priority wins regardless of registration order
+	c.RegisterProvider(&fakeProvider{"high", "http://challonge.com/"}, 10)
+	c.RegisterProvider(&fakeProvider{"later", "http://challonge.com/"}, 5)
+	assert.Equal(t, "high", c.ProviderFor(url).Name())
+}
@@ -0,0 +1,108 @@
+package bracket
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimit limits how often a client calls a web service. Requests are
+// allowed at a steady rate of Requests per Per, and up to Burst requests
+// may be made at once after a quiet period.
+type RateLimit struct {
+	Requests int
+	Per      time.Duration
+	// Burst defaults to 1 if unset.
+	Burst int
+}
+
+// WithRateLimit limits requests made by the named provider ("challonge",
+// "smashgg" or "startgg" for the built-in providers). Requests over the limit block
+// until they are allowed or their context is done. The limit is shared by
+// every goroutine using the client.
+func WithRateLimit(provider string, limit RateLimit) Option {
+	return func(c *Client) {
+		if c.rateLimiters == nil {
+			c.rateLimiters = make(map[string]*rateLimiter)
+		}
+		c.rateLimiters[provider] = newRateLimiter(limit)
+	}
+}
+
+// rateLimiter is a token bucket. Callers reserve a token up front, letting
+// the bucket go negative, and then sleep until their token would have been
+// available; this keeps waiting callers in roughly first-come order.
+type rateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	burst    float64
+	tokens   float64
+	last     time.Time
+}
+
+func newRateLimiter(limit RateLimit) *rateLimiter {
+	burst := limit.Burst
+	if burst < 1 {
+		burst = 1
+	}
+	requests := limit.Requests
+	if requests < 1 {
+		requests = 1
+	}
+	return &rateLimiter{
+		interval: limit.Per / time.Duration(requests),
+		burst:    float64(burst),
+		tokens:   float64(burst),
+		last:     time.Now(),
+	}
+}
+
+// reserve takes a token and returns how long the caller must wait
+// before using it.
+func (l *rateLimiter) reserve(now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.interval > 0 {
+		l.tokens += float64(now.Sub(l.last)) / float64(l.interval)
+	} else {
+		l.tokens = l.burst
+	}
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+
+	l.tokens--
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens * float64(l.interval))
+}
+
+// cancel returns a token that was reserved but not used.
+func (l *rateLimiter) cancel() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.tokens++
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+}
+
+// wait blocks until a request is allowed or ctx is done.
+func (l *rateLimiter) wait(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	delay := l.reserve(time.Now())
+	if delay == 0 {
+		return nil
+	}
+	if err := sleep(ctx, delay); err != nil {
+		l.cancel()
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package bracket
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiterReserve(t *testing.T) {
+	l := newRateLimiter(RateLimit{Requests: 10, Per: time.Second, Burst: 2})
+	now := l.last
+
+	// The burst is available immediately
+	assert.Equal(t, time.Duration(0), l.reserve(now))
+	assert.Equal(t, time.Duration(0), l.reserve(now))
+	// Then requests are spaced out at the steady rate
+	assert.Equal(t, 100*time.Millisecond, l.reserve(now))
+	assert.Equal(t, 200*time.Millisecond, l.reserve(now))
+
+	// Time passing pays back the debt
+	assert.Equal(t, 100*time.Millisecond, l.reserve(now.Add(200*time.Millisecond)))
+}
+
+func TestRateLimiterRefillCapsAtBurst(t *testing.T) {
+	l := newRateLimiter(RateLimit{Requests: 1, Per: time.Second, Burst: 2})
+	now := l.last.Add(time.Hour)
+	assert.Equal(t, time.Duration(0), l.reserve(now))
+	assert.Equal(t, time.Duration(0), l.reserve(now))
+	assert.Equal(t, time.Second, l.reserve(now))
+}
+
+func TestRateLimiterCancel(t *testing.T) {
+	l := newRateLimiter(RateLimit{Requests: 1, Per: time.Hour})
+	assert.NoError(t, l.wait(context.Background()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := l.wait(ctx)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	// The cancelled caller's reservation was handed back
+	assert.InDelta(t, 0, l.tokens, 0.01)
+}
+
+func TestRateLimitSharedAcrossGoroutines(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	c := New(
+		WithChallongeBaseURL(server.URL),
+		WithRateLimit("challonge", RateLimit{Requests: 1, Per: 20 * time.Millisecond, Burst: 1}),
+	)
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.FetchBracket("http://challonge.com/xyfuz5c3")
+		}()
+	}
+	wg.Wait()
+
+	assert.EqualValues(t, 4, requests)
+	// One request goes out immediately, the other three wait their turn
+	assert.True(t, time.Since(start) >= 60*time.Millisecond)
+}
+
+func TestRateLimitIsPerProvider(t *testing.T) {
+	c := New(WithRateLimit("challonge", RateLimit{Requests: 1, Per: time.Minute}))
+	assert.NotNil(t, c.rateLimiters["challonge"])
+	assert.Nil(t, c.rateLimiters["smashgg"])
+}
@@ -0,0 +1,100 @@
+package bracket
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// GameScore is the score of a single game within a match.
+type GameScore struct {
+	Player1Score int
+	Player2Score int
+}
+
+// MatchReport describes the result of a match to report to a bracket
+// service.
+type MatchReport struct {
+	// WinnerID is the ID of the player who won the match. If empty, only
+	// the scores are updated and the match stays open.
+	WinnerID string
+	// Scores holds the score of each game played, in order.
+	Scores []GameScore
+	// Disqualified marks the loser as disqualified, so WinnerID advances
+	// without the match being played.
+	Disqualified bool
+}
+
+// validate checks that the report makes sense before it's sent.
+func (r *MatchReport) validate() error {
+	if r.Disqualified && r.WinnerID == "" {
+		return errors.New("bracket: a disqualification needs a WinnerID")
+	}
+	return nil
+}
+
+// MatchReporter is implemented by providers that can report match
+// results back to their service.
+type MatchReporter interface {
+	// ReportMatch records the result of the match with the given ID in the
+	// bracket at bracketURL and returns the updated match.
+	ReportMatch(ctx context.Context, bracketURL, matchID string, r *MatchReport) (*Match, error)
+}
+
+// ReportMatch reports the result of a match previously fetched from the
+// bracket at bracketURL, and returns the updated match.
+func (c *Client) ReportMatch(ctx context.Context, bracketURL, matchID string, r *MatchReport) (*Match, error) {
+	p := c.ProviderFor(bracketURL)
+	if p == nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedURL, bracketURL)
+	}
+	reporter, ok := p.(MatchReporter)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s cannot report matches", ErrNotSupported, p.Name())
+	}
+	return reporter.ReportMatch(ctx, bracketURL, matchID, r)
+}
+
+// MatchUpdater is implemented by providers that can change the state of a
+// match without reporting a result.
+type MatchUpdater interface {
+	// MarkMatchInProgress marks the match with the given ID in the
+	// bracket at bracketURL as being played and returns the updated match.
+	MarkMatchInProgress(ctx context.Context, bracketURL, matchID string) (*Match, error)
+	// ResetMatch clears the result of the match with the given ID in the
+	// bracket at bracketURL, along with any matches that depended on it,
+	// and returns the updated match.
+	ResetMatch(ctx context.Context, bracketURL, matchID string) (*Match, error)
+}
+
+func (c *Client) matchUpdater(bracketURL string) (MatchUpdater, error) {
+	p := c.ProviderFor(bracketURL)
+	if p == nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedURL, bracketURL)
+	}
+	updater, ok := p.(MatchUpdater)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s cannot update matches", ErrNotSupported, p.Name())
+	}
+	return updater, nil
+}
+
+// MarkMatchInProgress marks a match previously fetched from the bracket at
+// bracketURL as being played, and returns the updated match.
+func (c *Client) MarkMatchInProgress(ctx context.Context, bracketURL, matchID string) (*Match, error) {
+	updater, err := c.matchUpdater(bracketURL)
+	if err != nil {
+		return nil, err
+	}
+	return updater.MarkMatchInProgress(ctx, bracketURL, matchID)
+}
+
+// ResetMatch clears the result of a match previously fetched from the
+// bracket at bracketURL, and returns the updated match.
+func (c *Client) ResetMatch(ctx context.Context, bracketURL, matchID string) (*Match, error) {
+	updater, err := c.matchUpdater(bracketURL)
+	if err != nil {
+		return nil, err
+	}
+	return updater.ResetMatch(ctx, bracketURL, matchID)
+}
@@ -0,0 +1,175 @@
+package bracket
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy controls how a Client retries requests that fail with a
+// transient error. Only idempotent requests (GET and HEAD, and read-only
+// GraphQL queries) are retried, and only after a network error or a 429,
+// 500, 502, 503 or 504 response.
+//
+// The zero value disables retries.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts made for a request,
+	// including the first one. Values below 2 disable retries.
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay between attempts, including a delay asked
+	// for by a Retry-After header. Zero means no cap.
+	MaxBackoff time.Duration
+	// Multiplier scales the delay after each attempt. Values below 1
+	// are treated as 2.
+	Multiplier float64
+	// Jitter randomizes each delay by up to this fraction of itself, so
+	// 0.2 gives delays between 80% and 120% of the computed backoff.
+	Jitter float64
+	// OnRetry, if set, is called before the client waits to retry.
+	OnRetry func(RetryAttempt)
+}
+
+// RetryAttempt describes a failed attempt that is about to be retried.
+type RetryAttempt struct {
+	// Provider is the name of the provider that made the request.
+	Provider string
+	// URL is the API URL that was requested.
+	URL string
+	// Attempt is the number of the attempt that failed, starting at 1.
+	Attempt int
+	// StatusCode is the HTTP status of the failed attempt, or 0 if the
+	// request failed without a response.
+	StatusCode int
+	// Err is the error the attempt failed with.
+	Err error
+	// Delay is how long the client will wait before the next attempt.
+	Delay time.Duration
+}
+
+// DefaultRetryPolicy is a reasonable policy for most callers: up to three
+// attempts, starting with a half-second delay.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    3,
+	InitialBackoff: 500 * time.Millisecond,
+	MaxBackoff:     30 * time.Second,
+	Multiplier:     2,
+	Jitter:         0.2,
+}
+
+// WithRetryPolicy sets the policy used to retry failed requests.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *Client) {
+		c.retryPolicy = policy
+	}
+}
+
+// backoff returns the delay to wait after the given failed attempt.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 2
+	}
+	delay := float64(p.InitialBackoff) * math.Pow(multiplier, float64(attempt-1))
+	if p.MaxBackoff > 0 && delay > float64(p.MaxBackoff) {
+		delay = float64(p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		delay += delay * p.Jitter * (2*rand.Float64() - 1)
+	}
+	return time.Duration(delay)
+}
+
+func isRetryableMethod(method string) bool {
+	return method == "GET" || method == "HEAD"
+}
+
+func isRetryableStatus(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// networkError wraps an error sending a request or reading its response,
+// which unlike an error building the request may succeed if retried.
+type networkError struct {
+	err error
+}
+
+func (e *networkError) Error() string { return e.err.Error() }
+func (e *networkError) Unwrap() error { return e.err }
+
+// isRetryableError reports whether err is worth retrying. Errors with an
+// HTTP status are retried depending on the status, and network errors and
+// timeouts are retried unless the context ended. Anything else, such as an
+// invalid URL, would only fail again.
+func isRetryableError(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return isRetryableStatus(apiErr.StatusCode)
+	}
+	var netErr *networkError
+	return errors.As(err, &netErr)
+}
+
+// retryDelay works out how long to wait after the given failed attempt,
+// preferring the server's Retry-After header to the policy's backoff.
+func (p RetryPolicy) retryDelay(attempt int, header http.Header, now time.Time) time.Duration {
+	delay := p.backoff(attempt)
+	if retryAfter, ok := parseRetryAfter(header.Get("Retry-After"), now); ok {
+		delay = retryAfter
+		if p.MaxBackoff > 0 && delay > p.MaxBackoff {
+			delay = p.MaxBackoff
+		}
+	}
+	return delay
+}
+
+// parseRetryAfter reads a Retry-After header given either in seconds or
+// as an HTTP date. It returns false if the header is missing or invalid.
+func parseRetryAfter(header string, now time.Time) (time.Duration, bool) {
+	if header == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(header); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if t, err := http.ParseTime(header); err == nil {
+		delay := t.Sub(now)
+		if delay < 0 {
+			delay = 0
+		}
+		return delay, true
+	}
+	return 0, false
+}
+
+// sleep waits for d, returning early with the context's error if ctx
+// is done first.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,164 @@
+package bracket
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryBackoff(t *testing.T) {
+	p := RetryPolicy{
+		InitialBackoff: time.Second,
+		MaxBackoff:     5 * time.Second,
+		Multiplier:     2,
+	}
+	assert.Equal(t, time.Second, p.backoff(1))
+	assert.Equal(t, 2*time.Second, p.backoff(2))
+	assert.Equal(t, 4*time.Second, p.backoff(3))
+	assert.Equal(t, 5*time.Second, p.backoff(4))
+
+	p.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		d := p.backoff(1)
+		assert.True(t, d >= 500*time.Millisecond && d <= 1500*time.Millisecond, "%v", d)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2016, 4, 2, 12, 0, 0, 0, time.UTC)
+
+	d, ok := parseRetryAfter("120", now)
+	assert.True(t, ok)
+	assert.Equal(t, 2*time.Minute, d)
+
+	d, ok = parseRetryAfter("Sat, 02 Apr 2016 12:00:30 GMT", now)
+	assert.True(t, ok)
+	assert.Equal(t, 30*time.Second, d)
+
+	_, ok = parseRetryAfter("", now)
+	assert.False(t, ok)
+	_, ok = parseRetryAfter("soon", now)
+	assert.False(t, ok)
+	_, ok = parseRetryAfter("-1", now)
+	assert.False(t, ok)
+}
+
+func TestRetryDelayCapsRetryAfter(t *testing.T) {
+	now := time.Date(2016, 4, 2, 12, 0, 0, 0, time.UTC)
+	p := RetryPolicy{InitialBackoff: time.Second, MaxBackoff: 30 * time.Second}
+	assert.Equal(t, 30*time.Second, p.retryDelay(1, http.Header{"Retry-After": {"7200"}}, now))
+	assert.Equal(t, 10*time.Second, p.retryDelay(1, http.Header{"Retry-After": {"10"}}, now))
+	assert.Equal(t, time.Second, p.retryDelay(1, http.Header{}, now))
+}
+
+func TestIsRetryableError(t *testing.T) {
+	ctx := context.Background()
+	assert.True(t, isRetryableError(ctx, &networkError{errors.New("connection reset")}))
+	assert.True(t, isRetryableError(ctx, newAPIError("challonge", http.StatusBadGateway, nil, nil)))
+	assert.False(t, isRetryableError(ctx, newAPIError("challonge", http.StatusNotFound, nil, nil)))
+	assert.False(t, isRetryableError(ctx, errors.New("parse \"::\": missing protocol scheme")))
+}
+
+func TestRetryTransientErrors(t *testing.T) {
+	fixture, err := ioutil.ReadFile("testdata/challonge.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch requests {
+		case 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case 2:
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			w.Write(fixture)
+		}
+	}))
+	defer server.Close()
+
+	var attempts []RetryAttempt
+	c := New(
+		WithChallongeBaseURL(server.URL),
+		WithRetryPolicy(RetryPolicy{
+			MaxAttempts:    3,
+			InitialBackoff: time.Millisecond,
+			OnRetry: func(a RetryAttempt) {
+				attempts = append(attempts, a)
+			},
+		}),
+	)
+	b, err := c.FetchBracket("http://challonge.com/xyfuz5c3")
+	assert.NoError(t, err)
+	assert.NotNil(t, b)
+	assert.Equal(t, 3, requests)
+
+	assert.Len(t, attempts, 2)
+	assert.Equal(t, "challonge", attempts[0].Provider)
+	assert.Equal(t, 1, attempts[0].Attempt)
+	assert.Equal(t, http.StatusServiceUnavailable, attempts[0].StatusCode)
+	assert.Equal(t, time.Millisecond, attempts[0].Delay)
+	assert.Equal(t, 2, attempts[1].Attempt)
+	assert.Equal(t, http.StatusTooManyRequests, attempts[1].StatusCode)
+	assert.Equal(t, time.Duration(0), attempts[1].Delay)
+}
+
+func TestRetryGivesUp(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	c := New(
+		WithChallongeBaseURL(server.URL),
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond}),
+	)
+	_, err := c.FetchBracket("http://challonge.com/xyfuz5c3")
+	var apiErr *APIError
+	assert.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusBadGateway, apiErr.StatusCode)
+	assert.Equal(t, 2, requests)
+}
+
+func TestRetrySkipsPermanentErrors(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	c := New(
+		WithChallongeBaseURL(server.URL),
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Millisecond}),
+	)
+	_, err := c.FetchBracket("http://challonge.com/xyfuz5c3")
+	assert.True(t, errors.Is(err, ErrNotFound))
+	assert.Equal(t, 1, requests)
+}
+
+func TestRetryStopsWhenContextDone(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	c := New(
+		WithChallongeBaseURL(server.URL),
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Hour}),
+	)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := c.FetchBracketContext(ctx, "http://challonge.com/xyfuz5c3")
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
@@ -0,0 +1,121 @@
+package bracket
+
+import "strconv"
+
+// MatchSide is the part of a bracket a match belongs to.
+type MatchSide string
+
+const (
+	// MatchSideWinners is the winners bracket, or the whole bracket in
+	// single elimination.
+	MatchSideWinners MatchSide = "winners"
+	// MatchSideLosers is the losers bracket of a double elimination
+	// bracket.
+	MatchSideLosers MatchSide = "losers"
+	// MatchSideGrandFinal is the grand final between the winners and
+	// losers bracket champions.
+	MatchSideGrandFinal MatchSide = "grand_final"
+	// MatchSideGrandFinalReset is the second grand final, played if the
+	// losers bracket champion wins the first.
+	MatchSideGrandFinalReset MatchSide = "grand_final_reset"
+	// MatchSideRoundRobin is a match in a round robin pool.
+	MatchSideRoundRobin MatchSide = "round_robin"
+	// MatchSideSwiss is a match in a swiss bracket.
+	MatchSideSwiss MatchSide = "swiss"
+)
+
+// IsGrandFinalReset reports whether the match is the second grand final of
+// a double elimination bracket.
+func (m *Match) IsGrandFinalReset() bool {
+	return m.Side == MatchSideGrandFinalReset
+}
+
+// assignSides sets the side and round label of every match in a bracket.
+// Both providers number losers rounds negatively, and link the grand final
+// to the losers bracket and the reset to the grand final through the
+// matches' prerequisites.
+func assignSides(matches []*Match, format BracketFormat) {
+	switch format {
+	case FormatRoundRobin, FormatSwiss:
+		side := MatchSideRoundRobin
+		if format == FormatSwiss {
+			side = MatchSideSwiss
+		}
+		for _, m := range matches {
+			m.Side = side
+			m.RoundLabel = "Round " + strconv.Itoa(m.Round)
+		}
+		return
+	}
+
+	byID := make(map[string]*Match, len(matches))
+	for _, m := range matches {
+		byID[m.ID] = m
+	}
+	fromLosers := func(id *string) bool {
+		return id != nil && byID[*id] != nil && byID[*id].Round < 0
+	}
+
+	grandFinals := make(map[string]bool)
+	for _, m := range matches {
+		if m.Round > 0 && (fromLosers(m.Player1PrereqMatchID) || fromLosers(m.Player2PrereqMatchID)) {
+			grandFinals[m.ID] = true
+		}
+	}
+
+	winnersRounds, losersRounds := 0, 0
+	for _, m := range matches {
+		p1, p2 := m.Player1PrereqMatchID, m.Player2PrereqMatchID
+		switch {
+		case m.Round < 0:
+			m.Side = MatchSideLosers
+			if -m.Round > losersRounds {
+				losersRounds = -m.Round
+			}
+		case grandFinals[m.ID]:
+			m.Side = MatchSideGrandFinal
+		case p1 != nil && p2 != nil && *p1 == *p2 && grandFinals[*p1]:
+			m.Side = MatchSideGrandFinalReset
+		default:
+			m.Side = MatchSideWinners
+			if m.Round > winnersRounds {
+				winnersRounds = m.Round
+			}
+		}
+	}
+
+	for _, m := range matches {
+		switch m.Side {
+		case MatchSideLosers:
+			m.RoundLabel = roundLabel("Losers ", -m.Round, losersRounds)
+		case MatchSideGrandFinal:
+			m.RoundLabel = "Grand Final"
+		case MatchSideGrandFinalReset:
+			m.RoundLabel = "Grand Final Reset"
+		default:
+			if m.Round == 0 {
+				// Challonge puts the third place match in round 0
+				m.RoundLabel = "Third Place Match"
+				continue
+			}
+			prefix := ""
+			if losersRounds > 0 {
+				prefix = "Winners "
+			}
+			m.RoundLabel = roundLabel(prefix, m.Round, winnersRounds)
+		}
+	}
+}
+
+// roundLabel names a round by how far it is from the last one.
+func roundLabel(prefix string, round, rounds int) string {
+	switch rounds - round {
+	case 0:
+		return prefix + "Final"
+	case 1:
+		return prefix + "Semi-Final"
+	case 2:
+		return prefix + "Quarter-Final"
+	}
+	return prefix + "Round " + strconv.Itoa(round)
+}
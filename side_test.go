@@ -0,0 +1,96 @@
+package bracket
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAssignSidesMatchesSmashGG(t *testing.T) {
+	b, err := ioutil.ReadFile("testdata/smashgg_58playerbracket.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := decodeSmashGGData(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var raw struct {
+		Entities struct {
+			Sets []struct {
+				ID            int    `json:"id"`
+				FullRoundText string `json:"fullRoundText"`
+			} `json:"sets"`
+		} `json:"entities"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		t.Fatal(err)
+	}
+	roundText := make(map[string]string)
+	for _, s := range raw.Entities.Sets {
+		roundText[strconv.Itoa(s.ID)] = s.FullRoundText
+	}
+
+	bracket := convertSmashGGData(resp)
+	sides := make(map[MatchSide]int)
+	for _, m := range bracket.Matches {
+		sides[m.Side]++
+		if m.IsGrandFinalReset() {
+			// smash.gg labels both grand finals the same
+			assert.Equal(t, "Grand Final Reset", m.RoundLabel)
+			assert.Equal(t, "4716520", m.ID)
+			continue
+		}
+		assert.Equal(t, roundText[m.ID], m.RoundLabel, m.ID)
+	}
+	assert.Equal(t, map[MatchSide]int{
+		MatchSideWinners:         63,
+		MatchSideLosers:          88,
+		MatchSideGrandFinal:      1,
+		MatchSideGrandFinalReset: 1,
+	}, sides)
+}
+
+func TestAssignSidesSingleElimination(t *testing.T) {
+	a, b := "a", "b"
+	matches := []*Match{
+		{ID: "a", Round: 1},
+		{ID: "b", Round: 1},
+		{ID: "c", Round: 2, Player1PrereqMatchID: &a, Player2PrereqMatchID: &b},
+		{ID: "d", Round: 0, Player1PrereqMatchID: &a, Player2PrereqMatchID: &b},
+	}
+	assignSides(matches, FormatSingleElimination)
+	assert.Equal(t, "Semi-Final", matches[0].RoundLabel)
+	assert.Equal(t, "Final", matches[2].RoundLabel)
+	assert.Equal(t, "Third Place Match", matches[3].RoundLabel)
+	for _, m := range matches {
+		assert.Equal(t, MatchSideWinners, m.Side)
+	}
+}
+
+func TestAssignSidesRoundRobin(t *testing.T) {
+	matches := []*Match{{ID: "a", Round: 1}, {ID: "b", Round: 3}}
+	assignSides(matches, FormatRoundRobin)
+	assert.Equal(t, MatchSideRoundRobin, matches[1].Side)
+	assert.Equal(t, "Round 3", matches[1].RoundLabel)
+
+	assignSides(matches, FormatSwiss)
+	assert.Equal(t, MatchSideSwiss, matches[0].Side)
+}
+
+func TestChallongeSides(t *testing.T) {
+	b, err := ioutil.ReadFile("testdata/challonge.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := decodeChallongeData(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bracket := convertChallongeData(resp, DefaultNameSeparators)
+	assert.Equal(t, MatchSideWinners, bracket.Matches[0].Side)
+	assert.Equal(t, "Final", bracket.Matches[0].RoundLabel)
+}
@@ -1,14 +1,18 @@
 package bracket
 
 import (
+	"context"
 	"encoding/json"
-	"io/ioutil"
-	"net/http"
+	"errors"
+	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 )
 
+const defaultSmashGGBaseURL = "https://smash.gg/api/-/resource/gg_api./"
+
 type smashGGAPIResponse struct {
 	Entities *smashGGEntities `json:"entities"`
 }
@@ -20,10 +24,47 @@ type smashGGEntities struct {
 }
 
 type smashGGGroup struct {
-	ID      int `json:"id"`
-	PhaseID int `json:"phaseId"`
-	WaveID  int `json:"waveId"`
-	State   int `json:"state"`
+	ID                int    `json:"id"`
+	PhaseID           int    `json:"phaseId"`
+	WaveID            int    `json:"waveId"`
+	State             int    `json:"state"`
+	GroupTypeID       int    `json:"groupTypeId"`
+	DisplayIdentifier string `json:"displayIdentifier"`
+}
+
+type smashGGTournamentResponse struct {
+	Entities *smashGGTournamentEntities `json:"entities"`
+}
+
+type smashGGTournamentEntities struct {
+	Tournament *smashGGTournament `json:"tournament"`
+	Events     []*smashGGEvent    `json:"event"`
+	Phases     []*smashGGPhase    `json:"phase"`
+}
+
+type smashGGTournament struct {
+	ID           int    `json:"id"`
+	Name         string `json:"name"`
+	Slug         string `json:"slug"`
+	StartAt      *int64 `json:"startAt"`
+	EndAt        *int64 `json:"endAt"`
+	VenueName    string `json:"venueName"`
+	VenueAddress string `json:"venueAddress"`
+	Timezone     string `json:"timezone"`
+}
+
+type smashGGEvent struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+}
+
+type smashGGPhase struct {
+	ID         int    `json:"id"`
+	EventID    int    `json:"eventId"`
+	Name       string `json:"name"`
+	PhaseOrder int    `json:"phaseOrder"`
+	GroupCount int    `json:"groupCount"`
 }
 
 type smashGGSet struct {
@@ -43,6 +84,24 @@ type smashGGSet struct {
 	Entrant1PrereqID   *int   `json:"entrant1PrereqId"`
 	Entrant2PrereqType string `json:"entrant2PrereqType"`
 	Entrant2PrereqID   *int   `json:"entrant2PrereqId"`
+
+	Games []*smashGGGame `json:"games"`
+}
+
+// smashGGGame is a game within a set. Its scores are stocks remaining, and
+// its selections are keyed by entrant ID and then participant ID.
+type smashGGGame struct {
+	OrderNum         int                                       `json:"orderNum"`
+	WinnerID         *int                                      `json:"winnerId"`
+	Entrant1P1Stocks *int                                      `json:"entrant1P1Stocks"`
+	Entrant2P1Stocks *int                                      `json:"entrant2P1Stocks"`
+	StageID          *int                                      `json:"stageId"`
+	Selections       map[string]map[string][]*smashGGSelection `json:"selections"`
+}
+
+type smashGGSelection struct {
+	SelectionType  string `json:"selectionType"`
+	SelectionValue int    `json:"selectionValue"`
 }
 
 type smashGGSeed struct {
@@ -87,27 +146,41 @@ type smashGGEntrant struct {
 	PlayerIds      map[string]int `json:"playerIds"`
 }
 
+type smashGGProvider struct {
+	client *Client
+}
+
+func (p *smashGGProvider) Name() string {
+	return "smashgg"
+}
+
+func (p *smashGGProvider) MatchURL(url string) bool {
+	return isSmashGGURL(url)
+}
+
+func (p *smashGGProvider) FetchBracket(ctx context.Context, url string) (*Bracket, error) {
+	return fetchSmashGGBracket(ctx, p.client, url)
+}
+
 func isSmashGGURL(url string) bool {
 	return strings.Contains(url, "smash.gg")
 }
 
-func getSmashGGAPIURL(url string) string {
+func getSmashGGAPIURL(baseURL, url string) string {
 	trimURL := strings.TrimRight(url, "/")
 	splitURL := strings.Split(trimURL, "/")
 	phaseGroup := splitURL[len(splitURL)-1]
-	return "https://smash.gg/api/-/resource/gg_api./phase_group/" + phaseGroup + ";expand=%5B%22sets%22%2C%22seeds%22%2C%22standings%22%5D;mutations=%5B%22playerData%22%5D;reset=false"
+	return baseURL + "phase_group/" + phaseGroup + ";expand=%5B%22sets%22%2C%22seeds%22%2C%22standings%22%5D;mutations=%5B%22playerData%22%5D;reset=false"
 }
 
-func fetchSmashGGData(apiURL string) (*smashGGAPIResponse, error) {
-	req, err := http.NewRequest("GET", apiURL, nil)
-	if err != nil {
-		return nil, err
-	}
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	body, err := ioutil.ReadAll(resp.Body)
+// getSmashGGTournamentAPIURL builds the API URL of the tournament a bracket
+// URL belongs to, expanded with its events and phases.
+func getSmashGGTournamentAPIURL(baseURL, slug string) string {
+	return baseURL + "tournament/" + slug + ";expand=%5B%22event%22%2C%22phase%22%5D"
+}
+
+func fetchSmashGGData(ctx context.Context, c *Client, apiURL string) (*smashGGAPIResponse, error) {
+	body, err := c.get(ctx, &apiRequest{Provider: "smashgg", URL: apiURL})
 	if err != nil {
 		return nil, err
 	}
@@ -124,27 +197,37 @@ func decodeSmashGGData(body []byte) (*smashGGAPIResponse, error) {
 	return &decoded, nil
 }
 
-func convertSmashGGState(stateNum int, hasEntrants bool) string {
-	if stateNum == 3 {
-		return "complete"
+// isSmashGGByeSet reports whether a set is a first round bye, which
+// smash.gg includes in the bracket even though it is never played.
+func isSmashGGByeSet(round int, prereqType1, prereqType2 string) bool {
+	if round != 1 && round != -1 {
+		return false
 	}
-	if stateNum == 1 && hasEntrants {
-		return "open"
+	return prereqType1 == "bye" || prereqType2 == "bye"
+}
+
+// isSmashGGEmptySet reports whether a set is a bye against a bye, which
+// smash.gg uses to pad out the bracket. No player ever reaches one.
+func isSmashGGEmptySet(prereqType1, prereqType2 string) bool {
+	return prereqType1 == "bye" && prereqType2 == "bye"
+}
+
+// smashGGOutcome works out the outcome of a converted set. smash.gg scores
+// both the empty side of a bye and a disqualified entrant -1.
+func smashGGOutcome(m *Match, round int, prereqType1, prereqType2 string) MatchOutcome {
+	if isSmashGGByeSet(round, prereqType1, prereqType2) {
+		return OutcomeBye
 	}
-	return "pending"
+	return scoredOutcome(m)
 }
 
 func convertSmashGGMatches(resp *smashGGAPIResponse) []*Match {
 	// smash gg seems to return a lot of junk matches, so let's
 	// filter them out.
-	// In particular, cases where there are byes in round 1
+	// In particular, byes against byes in round 1
 	var filteredSets []*smashGGSet
 	for _, s := range resp.Entities.Sets {
-		if s.Round == 1 || s.Round == -1 {
-			if s.Entrant1PrereqType != "bye" && s.Entrant2PrereqType != "bye" {
-				filteredSets = append(filteredSets, s)
-			}
-		} else {
+		if !isSmashGGEmptySet(s.Entrant1PrereqType, s.Entrant2PrereqType) {
 			filteredSets = append(filteredSets, s)
 		}
 	}
@@ -175,7 +258,8 @@ func convertSmashGGMatches(resp *smashGGAPIResponse) []*Match {
 			StartedAt:            startedAt,
 			UpdatedAt:            &updatedAt,
 			Round:                s.Round,
-			State:                convertSmashGGState(s.State, s.Entrant1ID != 0 && s.Entrant2ID != 0),
+			State:                convertSmashGGMatchState(s.State, s.Entrant1ID != 0 && s.Entrant2ID != 0),
+			RawState:             rawSmashGGState(s.State),
 			Player1ID:            strconv.Itoa(s.Entrant1ID),
 			Player1Score:         s.Entrant1Score,
 			Player1PrereqMatchID: p1prereq,
@@ -184,39 +268,132 @@ func convertSmashGGMatches(resp *smashGGAPIResponse) []*Match {
 			Player2PrereqMatchID: p2prereq,
 			WinnerID:             strconv.Itoa(s.WinnerID),
 			LoserID:              strconv.Itoa(s.LoserID),
+			Games:                convertSmashGGGames(s),
 		}
+		matches[i].Outcome = smashGGOutcome(matches[i], s.Round, s.Entrant1PrereqType, s.Entrant2PrereqType)
 	}
 	return matches
 }
 
+// convertSmashGGGames converts the games in a set. The legacy API only
+// returns character and stage IDs, so those are used in place of names.
+func convertSmashGGGames(s *smashGGSet) []Game {
+	var games []Game
+	for _, d := range s.Games {
+		g := Game{Number: d.OrderNum, WinnerID: "0"}
+		if d.WinnerID != nil {
+			g.WinnerID = strconv.Itoa(*d.WinnerID)
+		}
+		if d.Entrant1P1Stocks != nil {
+			g.Player1Score = *d.Entrant1P1Stocks
+		}
+		if d.Entrant2P1Stocks != nil {
+			g.Player2Score = *d.Entrant2P1Stocks
+		}
+		if d.StageID != nil {
+			g.Stage = strconv.Itoa(*d.StageID)
+		}
+		g.Player1Characters = smashGGCharacters(d.Selections[strconv.Itoa(s.Entrant1ID)])
+		g.Player2Characters = smashGGCharacters(d.Selections[strconv.Itoa(s.Entrant2ID)])
+		games = append(games, g)
+	}
+	return games
+}
+
+func smashGGCharacters(participants map[string][]*smashGGSelection) []string {
+	// sort participants so doubles teams list characters in a stable order
+	ids := make([]string, 0, len(participants))
+	for id := range participants {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var characters []string
+	for _, id := range ids {
+		for _, sel := range participants[id] {
+			if sel.SelectionType == "CHARACTER" {
+				characters = append(characters, strconv.Itoa(sel.SelectionValue))
+			}
+		}
+	}
+	return characters
+}
+
 func convertSmashGGPlayers(resp *smashGGAPIResponse) []*Player {
 	players := make([]*Player, len(resp.Entities.Seeds))
 	for i, p := range resp.Entities.Seeds {
 		entrantID := strconv.Itoa(p.EntrantID)
+		entrant := p.Mutations.Entrants[entrantID]
 		players[i] = &Player{
 			ID:   entrantID,
-			Name: p.Mutations.Entrants[entrantID].Name,
+			Name: entrant.Name,
 			Seed: p.SeedNum,
 			Rank: p.Placement,
 		}
+		if len(entrant.ParticipantIds) == 1 {
+			addSmashGGIdentity(players[i], p.Mutations, entrant, entrant.ParticipantIds[0])
+			continue
+		}
+		for _, id := range entrant.ParticipantIds {
+			member := &Player{ID: strconv.Itoa(id)}
+			addSmashGGIdentity(member, p.Mutations, entrant, id)
+			member.Name = sponsoredName(member.Prefix, member.GamerTag)
+			players[i].Members = append(players[i].Members, member)
+		}
 	}
 	return players
 }
 
+// addSmashGGIdentity fills in who a player is from the participant and
+// player mutations. Participants hold what was entered for the
+// tournament, so they take precedence over the player's profile.
+func addSmashGGIdentity(player *Player, mutations *smashGGMutations, entrant *smashGGEntrant, participantID int) {
+	pid := strconv.Itoa(participantID)
+	if p := mutations.Participants[pid]; p != nil {
+		player.GamerTag = p.GamerTag
+		player.Prefix = p.Prefix
+		if p.ContactInfo != nil {
+			player.RealName = strings.TrimSpace(p.ContactInfo.NameFirst + " " + p.ContactInfo.NameLast)
+		}
+	}
+	playerID, ok := entrant.PlayerIds[pid]
+	if !ok {
+		return
+	}
+	player.AccountID = strconv.Itoa(playerID)
+	if p := mutations.Players[player.AccountID]; p != nil {
+		if player.GamerTag == "" {
+			player.GamerTag = p.GamerTag
+			player.Prefix = p.Prefix
+		}
+		if player.RealName == "" {
+			player.RealName = p.Name
+		}
+		player.Country = p.Country
+		player.Region = p.State
+	}
+}
+
 func convertSmashGGData(resp *smashGGAPIResponse) *Bracket {
 	// Build tournament state
-	state := ""
+	state := BracketStatePending
+	rawState := ""
+	format := FormatUnknown
 	if resp.Entities != nil && resp.Entities.Groups != nil {
-		state = convertSmashGGState(resp.Entities.Groups.State, true)
+		state = convertSmashGGBracketState(resp.Entities.Groups.State)
+		rawState = rawSmashGGState(resp.Entities.Groups.State)
+		format = convertSmashGGFormat(resp.Entities.Groups.GroupTypeID)
 	}
 
 	b := &Bracket{
-		Name:    "", // API does not return a tournament name
-		URL:     "", // API does not return a tournament URL
-		State:   state,
-		Matches: convertSmashGGMatches(resp),
-		Players: convertSmashGGPlayers(resp),
+		State:    state,
+		RawState: rawState,
+		Matches:  convertSmashGGMatches(resp),
+		Players:  convertSmashGGPlayers(resp),
 	}
+	b.Format = format
+	assignSides(b.Matches, format)
+	b.FormatSettings = smashGGFormatSettings(format, b.Matches)
 
 	// API does not return updatedAt or startedAt on tournament, so
 	// attempt to pull that off of the matches
@@ -232,14 +409,100 @@ func convertSmashGGData(resp *smashGGAPIResponse) *Bracket {
 	return b
 }
 
-func fetchSmashGGBracket(url string) (*Bracket, error) {
-	apiURL := getSmashGGAPIURL(url)
-	resp, err := fetchSmashGGData(apiURL)
+func fetchSmashGGBracket(ctx context.Context, c *Client, url string) (*Bracket, error) {
+	apiURL := getSmashGGAPIURL(c.smashGGBaseURL, url)
+	resp, err := fetchSmashGGData(ctx, c, apiURL)
 	if err != nil {
 		return nil, err
 	}
 
 	b := convertSmashGGData(resp)
 	b.URL = url
+	if resp.Entities == nil || resp.Entities.Groups == nil || resp.Entities.Groups.PhaseID == 0 {
+		return b, nil
+	}
+	slug, _, err := getStartGGSlugs(url)
+	if err != nil {
+		return b, nil
+	}
+	tournament, err := fetchSmashGGTournament(ctx, c, slug)
+	if err != nil {
+		// the bracket is still useful without its name, so a tournament
+		// the service won't return leaves it unnamed rather than failing
+		// the fetch
+		if ctx.Err() != nil || !isSmashGGTournamentMissing(err) {
+			return nil, err
+		}
+		return b, nil
+	}
+	addSmashGGTournament(b, resp.Entities.Groups, tournament)
 	return b, nil
 }
+
+// isSmashGGTournamentMissing reports whether err means the service has no
+// details for a tournament, as opposed to the request itself failing.
+func isSmashGGTournamentMissing(err error) bool {
+	var apiErr *APIError
+	if errors.Is(err, ErrNotFound) {
+		return true
+	}
+	return errors.As(err, &apiErr) && !errors.Is(err, ErrUnauthorized) && !errors.Is(err, ErrRateLimited)
+}
+
+// fetchSmashGGTournament fetches the details of the tournament with the
+// given slug.
+func fetchSmashGGTournament(ctx context.Context, c *Client, slug string) (*smashGGTournamentEntities, error) {
+	body, err := c.get(ctx, &apiRequest{Provider: "smashgg", URL: getSmashGGTournamentAPIURL(c.smashGGBaseURL, slug)})
+	if err != nil {
+		return nil, err
+	}
+	var resp smashGGTournamentResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Entities == nil || resp.Entities.Tournament == nil {
+		return nil, fmt.Errorf("%w: smash.gg tournament %s", ErrNotFound, slug)
+	}
+	return resp.Entities, nil
+}
+
+// addSmashGGTournament fills in the tournament, event and phase a bracket
+// belongs to, along with its name and canonical URL, which the phase group
+// API doesn't return.
+func addSmashGGTournament(b *Bracket, group *smashGGGroup, entities *smashGGTournamentEntities) {
+	if entities == nil || entities.Tournament == nil {
+		return
+	}
+	t := entities.Tournament
+	b.Tournament = &Tournament{
+		ID:           strconv.Itoa(t.ID),
+		Name:         t.Name,
+		URL:          startGGURL + t.Slug,
+		StartAt:      convertStartGGTime(t.StartAt),
+		EndAt:        convertStartGGTime(t.EndAt),
+		Venue:        t.VenueName,
+		VenueAddress: t.VenueAddress,
+		Timezone:     t.Timezone,
+	}
+
+	var phase *smashGGPhase
+	for _, p := range entities.Phases {
+		if p.ID == group.PhaseID {
+			phase = p
+		}
+	}
+	if phase == nil {
+		b.Name = t.Name
+		return
+	}
+	b.Phase = &Phase{ID: strconv.Itoa(phase.ID), Name: phase.Name, Order: phase.PhaseOrder}
+	for _, e := range entities.Events {
+		if e.ID == phase.EventID {
+			b.Event = &TournamentEvent{ID: strconv.Itoa(e.ID), Name: e.Name, URL: startGGURL + e.Slug}
+			b.Name = bracketName(t.Name, e.Name, phase.Name, group.DisplayIdentifier, phase.GroupCount)
+			b.URL = startGGBracketURL(e.Slug, b.Phase.ID, strconv.Itoa(group.ID))
+			return
+		}
+	}
+	b.Name = bracketName(t.Name, "", phase.Name, group.DisplayIdentifier, phase.GroupCount)
+}
@@ -1,7 +1,12 @@
 package bracket
 
 import (
+	"encoding/json"
+	"errors"
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -16,7 +21,7 @@ func TestIsSmashGGURL(t *testing.T) {
 func TestGetSmashGGAPIURL(t *testing.T) {
 	url := "https://smash.gg/tournament/super-smash-sundays-48/brackets/14221/50133/165583"
 	apiURL := "https://smash.gg/api/-/resource/gg_api./phase_group/165583;expand=%5B%22sets%22%2C%22seeds%22%2C%22standings%22%5D;mutations=%5B%22playerData%22%5D;reset=false"
-	assert.Equal(t, apiURL, getSmashGGAPIURL(url))
+	assert.Equal(t, apiURL, getSmashGGAPIURL(defaultSmashGGBaseURL, url))
 }
 
 func TestDecodeSmashGGData(t *testing.T) {
@@ -184,7 +189,8 @@ func TestConvertSmashGGData(t *testing.T) {
 
 	assert.Equal(t, "", bracket.Name)
 	assert.Equal(t, "", bracket.URL)
-	assert.Equal(t, "complete", bracket.State)
+	assert.Equal(t, BracketStateComplete, bracket.State)
+	assert.Equal(t, "3", bracket.RawState)
 	updatedAt := time.Unix(1468187020, 0)
 	assert.Equal(t, &updatedAt, bracket.UpdatedAt)
 	assert.Nil(t, bracket.StartedAt)
@@ -197,11 +203,21 @@ func TestConvertSmashGGData(t *testing.T) {
 	assert.Equal(t, "TA | CDK", player.Name)
 	assert.Equal(t, 3, player.Rank)
 	assert.Equal(t, 7, player.Seed)
+	assert.Equal(t, "CDK", player.GamerTag)
+	assert.Equal(t, "TA", player.Prefix)
+	assert.Equal(t, "Connor Nguyen", player.RealName)
+	assert.Equal(t, "United States", player.Country)
+	assert.Equal(t, "CA", player.Region)
+	assert.Equal(t, "1092", player.AccountID)
 	player = players[1]
 	assert.Equal(t, "211974", player.ID)
 	assert.Equal(t, "A-Dar", player.Name)
 	assert.Equal(t, 5, player.Rank)
 	assert.Equal(t, 70, player.Seed)
+	assert.Equal(t, "A-Dar", player.GamerTag)
+	assert.Equal(t, "", player.Prefix)
+	assert.Equal(t, "US", player.Country)
+	assert.Equal(t, "14453", player.AccountID)
 	player = players[2]
 	assert.Equal(t, "212928", player.ID)
 	assert.Equal(t, "Slime", player.Name)
@@ -217,7 +233,7 @@ func TestConvertSmashGGData(t *testing.T) {
 	assert.Equal(t, "A", match.Identifier)
 	assert.Equal(t, 1, match.Round)
 	assert.Equal(t, &updatedAt, match.UpdatedAt)
-	assert.Equal(t, "complete", match.State)
+	assert.Equal(t, MatchStateComplete, match.State)
 	assert.Equal(t, "211768", match.Player1ID)
 	assert.Equal(t, 2, match.Player1Score)
 	assert.Equal(t, "212928", match.Player2ID)
@@ -231,7 +247,7 @@ func TestConvertSmashGGData(t *testing.T) {
 	assert.Equal(t, 2, match.Round)
 	assert.Equal(t, &updatedAt, match.UpdatedAt)
 	assert.Nil(t, match.StartedAt)
-	assert.Equal(t, "complete", match.State)
+	assert.Equal(t, MatchStateComplete, match.State)
 	assert.Equal(t, "211768", match.Player1ID)
 	assert.Equal(t, 2, match.Player1Score)
 	assert.Equal(t, "4689059", *match.Player1PrereqMatchID)
@@ -255,8 +271,16 @@ func TestSmashGGFilterMatches(t *testing.T) {
 	bracket := convertSmashGGData(resp)
 
 	assert.Len(t, bracket.Players, 58)
-	// number of matches in a double elim tournament: (n-1) * 2 + 1
-	assert.Len(t, bracket.Matches, 115)
+	// number of matches in a double elim tournament: (n-1) * 2 + 1,
+	// plus the byes of players without a first round opponent
+	byes := 0
+	for _, m := range bracket.Matches {
+		if m.Outcome == OutcomeBye {
+			byes++
+		}
+	}
+	assert.Len(t, bracket.Matches, 115+byes)
+	assert.Equal(t, 38, byes)
 }
 
 func TestSmashGGStartedAt(t *testing.T) {
@@ -293,3 +317,137 @@ func TestSmashGGPrereqID(t *testing.T) {
 	assert.Nil(t, bracket.Matches[1].Player1PrereqMatchID)
 	assert.EqualValues(t, *bracket.Matches[1].Player2PrereqMatchID, "2")
 }
+
+func TestConvertSmashGGGames(t *testing.T) {
+	var set smashGGSet
+	err := json.Unmarshal([]byte(`{
+		"id": 1,
+		"entrant1Id": 10,
+		"entrant2Id": 20,
+		"games": [{
+			"orderNum": 1,
+			"winnerId": 20,
+			"entrant1P1Stocks": 0,
+			"entrant2P1Stocks": 2,
+			"stageId": 3,
+			"selections": {
+				"10": {"100": [{"selectionType": "CHARACTER", "selectionValue": 2}]},
+				"20": {"200": [{"selectionType": "CHARACTER", "selectionValue": 9}]}
+			}
+		}]
+	}`), &set)
+	assert.NoError(t, err)
+
+	matches := convertSmashGGMatches(&smashGGAPIResponse{Entities: &smashGGEntities{Sets: []*smashGGSet{&set}}})
+	assert.Equal(t, []Game{{
+		Number:            1,
+		WinnerID:          "20",
+		Player1Score:      0,
+		Player2Score:      2,
+		Player1Characters: []string{"2"},
+		Player2Characters: []string{"9"},
+		Stage:             "3",
+	}}, matches[0].Games)
+}
+
+func TestFetchSmashGGBracketTournament(t *testing.T) {
+	tournamentRequests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/phase_group/171722") {
+			http.ServeFile(w, r, "testdata/smashgg.json")
+			return
+		}
+		tournamentRequests++
+		assert.Equal(t, "/tournament/super-smash-sundays-48;expand=[\"event\",\"phase\"]", r.URL.Path)
+		w.Write([]byte(`{"entities":{
+			"tournament":{"id":4563,"name":"Super Smash Sundays 48","slug":"tournament/super-smash-sundays-48","timezone":"America/Los_Angeles"},
+			"event":[{"id":14220,"name":"Melee Doubles","slug":"tournament/super-smash-sundays-48/event/melee-doubles"},
+				{"id":14221,"name":"Melee Singles","slug":"tournament/super-smash-sundays-48/event/melee-singles"}],
+			"phase":[{"id":50132,"eventId":14221,"name":"Pools","phaseOrder":1,"groupCount":16},
+				{"id":52212,"eventId":14221,"name":"Top 8","phaseOrder":2,"groupCount":1}]}}`))
+	}))
+	defer server.Close()
+
+	c := New(
+		WithSmashGGBaseURL(server.URL+"/"),
+		WithCache(CachePolicy{Cache: NewMemoryCache(10), TTL: time.Hour}),
+	)
+	b, err := c.FetchBracket("https://smash.gg/tournament/super-smash-sundays-48/brackets/14221/50132/171722")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "Super Smash Sundays 48 – Melee Singles – Pools A3", b.Name)
+	assert.Equal(t, "https://www.start.gg/tournament/super-smash-sundays-48/event/melee-singles/brackets/50132/171722", b.URL)
+	assert.Equal(t, "America/Los_Angeles", b.Tournament.Timezone)
+	assert.Equal(t, "Melee Singles", b.Event.Name)
+	assert.Equal(t, "Pools", b.Phase.Name)
+
+	// the cache saves fetching the tournament again
+	_, err = c.FetchBracket("https://smash.gg/tournament/super-smash-sundays-48/brackets/14221/50132/171722")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, tournamentRequests)
+}
+
+func TestFetchSmashGGBracketWithoutTournament(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/phase_group/171722") {
+			http.ServeFile(w, r, "testdata/smashgg.json")
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	c := New(WithSmashGGBaseURL(server.URL + "/"))
+	url := "https://smash.gg/tournament/super-smash-sundays-48/brackets/14221/50132/171722"
+	b, err := c.FetchBracket(url)
+	if !assert.NoError(t, err) {
+		return
+	}
+	// the bracket is still returned, just without its name
+	assert.Equal(t, url, b.URL)
+	assert.Equal(t, "", b.Name)
+	assert.Nil(t, b.Tournament)
+	assert.NotEmpty(t, b.Matches)
+}
+
+func TestFetchSmashGGBracketTournamentUnauthorized(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/phase_group/171722") {
+			http.ServeFile(w, r, "testdata/smashgg.json")
+			return
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	c := New(WithSmashGGBaseURL(server.URL + "/"))
+	_, err := c.FetchBracket("https://smash.gg/tournament/super-smash-sundays-48/brackets/14221/50132/171722")
+	assert.True(t, errors.Is(err, ErrUnauthorized))
+}
+
+func TestConvertSmashGGTeamPlayers(t *testing.T) {
+	var resp smashGGAPIResponse
+	err := json.Unmarshal([]byte(`{"entities":{"seeds":[{"entrantId":300,"seedNum":1,"placement":1,"mutations":{
+		"entrants":{"300":{"id":300,"name":"TA | CDK / Slime","participantIds":[238181,239067],"playerIds":{"238181":1092,"239067":13963}}},
+		"participants":{
+			"238181":{"id":238181,"gamerTag":"CDK","prefix":"TA"},
+			"239067":{"id":239067,"gamerTag":"Slime","prefix":""}},
+		"players":{
+			"1092":{"id":1092,"gamerTag":"CDK","prefix":"TA","name":"Connor Nguyen","state":"CA","country":"United States"},
+			"13963":{"id":13963,"gamerTag":"Slime","name":"Anthony Bruno","state":"CA","country":"United States"}}}}]}}`), &resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	players := convertSmashGGPlayers(&resp)
+	assert.Len(t, players, 1)
+	team := players[0]
+	assert.Equal(t, "300", team.ID)
+	assert.Equal(t, "TA | CDK / Slime", team.Name)
+	assert.Equal(t, "", team.GamerTag)
+	assert.Equal(t, []*Player{
+		{ID: "238181", Name: "TA | CDK", GamerTag: "CDK", Prefix: "TA", RealName: "Connor Nguyen", Country: "United States", Region: "CA", AccountID: "1092"},
+		{ID: "239067", Name: "Slime", GamerTag: "Slime", RealName: "Anthony Bruno", Country: "United States", Region: "CA", AccountID: "13963"},
+	}, team.Members)
+}
@@ -0,0 +1,667 @@
+package bracket
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultStartGGEndpoint = "https://api.start.gg/gql/alpha"
+
+// start.gg caps how many objects a single query may return, so phase
+// groups are fetched a page at a time. Page sizes are powers of two so
+// they can be halved without losing track of the offset.
+const (
+	startGGSeedsPerPage = 64
+	startGGSetsPerPage  = 32
+)
+
+const startGGSeedsQuery = `query PhaseGroupSeeds($id: ID!, $page: Int!, $perPage: Int!) {
+  phaseGroup(id: $id) {
+    id
+    displayIdentifier
+    state
+    bracketType
+    phase {
+      id
+      name
+      phaseOrder
+      groupCount
+      event {
+        id
+        name
+        slug
+        tournament {` + startGGTournamentFields + `}
+      }
+    }
+    wave { id }
+    seeds(query: {page: $page, perPage: $perPage}) {
+      pageInfo { total totalPages }
+      nodes {
+        id
+        seedNum
+        placement
+        entrant {
+          id
+          name
+          participants {
+            id
+            gamerTag
+            prefix
+            player { id }
+            user {
+              name
+              location { country state }
+            }
+          }
+        }
+      }
+    }
+  }
+}`
+
+const startGGSetsQuery = `query PhaseGroupSets($id: ID!, $page: Int!, $perPage: Int!) {
+  phaseGroup(id: $id) {
+    id
+    sets(page: $page, perPage: $perPage, sortType: STANDARD) {
+      pageInfo { total totalPages }
+      nodes {` + startGGSetFields + `}
+    }
+  }
+}`
+
+// startGGSetFields selects the fields of a set needed to build a Match.
+const startGGSetFields = `
+        id
+        identifier
+        round
+        state
+        startedAt
+        completedAt
+        winnerId
+        slots {
+          prereqType
+          prereqId
+          entrant { id }
+          standing { stats { score { value } } }
+        }
+        games {
+          orderNum
+          winnerId
+          entrant1Score
+          entrant2Score
+          stage { name }
+          selections {
+            entrant { id }
+            character { name }
+          }
+        }
+`
+
+type startGGRequest struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables"`
+}
+
+type startGGResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []*startGGError `json:"errors"`
+}
+
+type startGGError struct {
+	Message string `json:"message"`
+}
+
+// startGGID is a GraphQL ID, which start.gg sends as a number for most
+// objects but as a string for placeholders like preview sets.
+type startGGID string
+
+func (id *startGGID) UnmarshalJSON(b []byte) error {
+	if len(b) > 0 && b[0] == '"' {
+		var s string
+		if err := json.Unmarshal(b, &s); err != nil {
+			return err
+		}
+		*id = startGGID(s)
+		return nil
+	}
+	var n json.Number
+	if err := json.Unmarshal(b, &n); err != nil {
+		return err
+	}
+	*id = startGGID(n.String())
+	return nil
+}
+
+type startGGPhaseGroupData struct {
+	PhaseGroup *startGGPhaseGroup `json:"phaseGroup"`
+}
+
+type startGGPhaseGroup struct {
+	ID                startGGID              `json:"id"`
+	DisplayIdentifier string                 `json:"displayIdentifier"`
+	State             int                    `json:"state"`
+	BracketType       string                 `json:"bracketType"`
+	Phase             *startGGPhase          `json:"phase"`
+	Wave              *startGGWave           `json:"wave"`
+	Seeds             *startGGSeedConnection `json:"seeds"`
+	Sets              *startGGSetConnection  `json:"sets"`
+}
+
+type startGGPhase struct {
+	ID         startGGID     `json:"id"`
+	Name       string        `json:"name"`
+	PhaseOrder int           `json:"phaseOrder"`
+	GroupCount int           `json:"groupCount"`
+	Event      *startGGEvent `json:"event"`
+}
+
+type startGGWave struct {
+	ID startGGID `json:"id"`
+}
+
+type startGGPageInfo struct {
+	Total      int `json:"total"`
+	TotalPages int `json:"totalPages"`
+}
+
+type startGGSeedConnection struct {
+	PageInfo *startGGPageInfo `json:"pageInfo"`
+	Nodes    []*startGGSeed   `json:"nodes"`
+}
+
+type startGGSetConnection struct {
+	PageInfo *startGGPageInfo `json:"pageInfo"`
+	Nodes    []*startGGSet    `json:"nodes"`
+}
+
+type startGGSeed struct {
+	ID        startGGID       `json:"id"`
+	SeedNum   int             `json:"seedNum"`
+	Placement int             `json:"placement"`
+	Entrant   *startGGEntrant `json:"entrant"`
+}
+
+type startGGEntrant struct {
+	ID           startGGID             `json:"id"`
+	Name         string                `json:"name"`
+	Participants []*startGGParticipant `json:"participants"`
+}
+
+type startGGParticipant struct {
+	ID       startGGID      `json:"id"`
+	GamerTag string         `json:"gamerTag"`
+	Prefix   string         `json:"prefix"`
+	Player   *startGGPlayer `json:"player"`
+	User     *startGGUser   `json:"user"`
+}
+
+type startGGPlayer struct {
+	ID startGGID `json:"id"`
+}
+
+type startGGUser struct {
+	Name     string           `json:"name"`
+	Location *startGGLocation `json:"location"`
+}
+
+type startGGLocation struct {
+	Country string `json:"country"`
+	State   string `json:"state"`
+}
+
+type startGGSet struct {
+	ID          startGGID      `json:"id"`
+	Identifier  string         `json:"identifier"`
+	Round       int            `json:"round"`
+	State       int            `json:"state"`
+	StartedAt   *int64         `json:"startedAt"`
+	CompletedAt *int64         `json:"completedAt"`
+	WinnerID    *int           `json:"winnerId"`
+	Slots       []*startGGSlot `json:"slots"`
+	Games       []*startGGGame `json:"games"`
+}
+
+type startGGGame struct {
+	OrderNum      int                 `json:"orderNum"`
+	WinnerID      *int                `json:"winnerId"`
+	Entrant1Score *int                `json:"entrant1Score"`
+	Entrant2Score *int                `json:"entrant2Score"`
+	Stage         *startGGStage       `json:"stage"`
+	Selections    []*startGGSelection `json:"selections"`
+}
+
+type startGGStage struct {
+	Name string `json:"name"`
+}
+
+type startGGSelection struct {
+	Entrant   *startGGEntrant   `json:"entrant"`
+	Character *startGGCharacter `json:"character"`
+}
+
+type startGGCharacter struct {
+	Name string `json:"name"`
+}
+
+type startGGSlot struct {
+	PrereqType string           `json:"prereqType"`
+	PrereqID   *startGGID       `json:"prereqId"`
+	Entrant    *startGGEntrant  `json:"entrant"`
+	Standing   *startGGStanding `json:"standing"`
+}
+
+type startGGStanding struct {
+	Stats *startGGStats `json:"stats"`
+}
+
+type startGGStats struct {
+	Score *startGGScore `json:"score"`
+}
+
+type startGGScore struct {
+	Value *float64 `json:"value"`
+}
+
+type startGGProvider struct {
+	client *Client
+}
+
+func (p *startGGProvider) Name() string {
+	return "startgg"
+}
+
+// MatchURL matches start.gg URLs, and old smash.gg URLs once the client has
+// a start.gg token. Without a token, smash.gg URLs are left to the legacy
+// smash.gg provider.
+func (p *startGGProvider) MatchURL(url string) bool {
+	if isStartGGURL(url) {
+		return true
+	}
+	return p.client.startGGToken != "" && isSmashGGURL(url)
+}
+
+func (p *startGGProvider) FetchBracket(ctx context.Context, url string) (*Bracket, error) {
+	return fetchStartGGBracket(ctx, p.client, url)
+}
+
+func isStartGGURL(url string) bool {
+	return strings.Contains(url, "start.gg")
+}
+
+// getStartGGPhaseGroupID pulls the phase group ID off the end of a bracket
+// URL such as https://www.start.gg/tournament/x/event/y/brackets/50133/165583.
+func getStartGGPhaseGroupID(url string) (string, error) {
+	trimURL := strings.TrimRight(url, "/")
+	splitURL := strings.Split(trimURL, "/")
+	phaseGroup := splitURL[len(splitURL)-1]
+	if _, err := strconv.Atoi(phaseGroup); err != nil {
+		return "", fmt.Errorf("%w: %s does not link to a phase group", ErrUnsupportedURL, url)
+	}
+	return phaseGroup, nil
+}
+
+func (c *Client) setStartGGAuth(req *http.Request) {
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.startGGToken)
+}
+
+// decodeStartGGErrors pulls the messages out of a start.gg error payload,
+// which is either a GraphQL {"errors":[{"message":"..."}]} or a plain
+// {"message":"..."}.
+func decodeStartGGErrors(body []byte) []string {
+	var decoded struct {
+		startGGResponse
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		return nil
+	}
+	var messages []string
+	for _, e := range decoded.Errors {
+		messages = append(messages, e.Message)
+	}
+	if decoded.Message != "" {
+		messages = append(messages, decoded.Message)
+	}
+	return messages
+}
+
+// startGGQuery runs a read-only GraphQL query and decodes its data into out.
+// GraphQL errors are returned as an *APIError.
+func startGGQuery(ctx context.Context, c *Client, query string, variables map[string]interface{}, out interface{}) error {
+	return startGGPost(ctx, c, query, variables, true, out)
+}
+
+// startGGMutate runs a GraphQL mutation, which unlike a query is never
+// retried.
+func startGGMutate(ctx context.Context, c *Client, query string, variables map[string]interface{}, out interface{}) error {
+	return startGGPost(ctx, c, query, variables, false, out)
+}
+
+func startGGPost(ctx context.Context, c *Client, query string, variables map[string]interface{}, idempotent bool, out interface{}) error {
+	reqBody, err := json.Marshal(startGGRequest{query, variables})
+	if err != nil {
+		return err
+	}
+	resp, err := c.do(ctx, &apiRequest{
+		Provider:     "startgg",
+		Method:       "POST",
+		URL:          c.startGGEndpoint,
+		Body:         reqBody,
+		Idempotent:   idempotent,
+		Setup:        c.setStartGGAuth,
+		DecodeErrors: decodeStartGGErrors,
+	})
+	if err != nil {
+		return err
+	}
+	return decodeStartGGData(resp.StatusCode, resp.Body, out)
+}
+
+func decodeStartGGData(statusCode int, body []byte, out interface{}) error {
+	var decoded startGGResponse
+	if err := json.Unmarshal(body, &decoded); err != nil {
+		return err
+	}
+	if len(decoded.Errors) > 0 {
+		return newAPIError("startgg", statusCode, body, decodeStartGGErrors(body))
+	}
+	return json.Unmarshal(decoded.Data, out)
+}
+
+func isStartGGComplexityError(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	for _, m := range apiErr.Messages {
+		if strings.Contains(strings.ToLower(m), "complexity") {
+			return true
+		}
+	}
+	return false
+}
+
+// fetchStartGGPages runs a paginated phase group query until every page
+// has been fetched, passing each page to collect, which returns the total
+// number of pages. If start.gg rejects a page as too complex, the query
+// continues with pages half the size.
+func fetchStartGGPages(ctx context.Context, c *Client, query, phaseGroupID string, perPage int, collect func(*startGGPhaseGroup) int) error {
+	for page := 1; ; page++ {
+		var data startGGPhaseGroupData
+		err := startGGQuery(ctx, c, query, map[string]interface{}{
+			"id":      phaseGroupID,
+			"page":    page,
+			"perPage": perPage,
+		}, &data)
+		if err != nil && perPage > 1 && isStartGGComplexityError(err) {
+			// Everything before this page has been collected, which is
+			// the first 2*(page-1) pages at the smaller size. The loop
+			// moves on to the page after that.
+			perPage /= 2
+			page = 2 * (page - 1)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if data.PhaseGroup == nil {
+			return fmt.Errorf("%w: start.gg phase group %s", ErrNotFound, phaseGroupID)
+		}
+
+		if totalPages := collect(data.PhaseGroup); page >= totalPages {
+			return nil
+		}
+	}
+}
+
+// fetchStartGGData fetches a phase group with all of its seeds and sets.
+func fetchStartGGData(ctx context.Context, c *Client, phaseGroupID string) (*startGGPhaseGroup, error) {
+	var group *startGGPhaseGroup
+	var seeds []*startGGSeed
+	var sets []*startGGSet
+
+	err := fetchStartGGPages(ctx, c, startGGSeedsQuery, phaseGroupID, startGGSeedsPerPage, func(g *startGGPhaseGroup) int {
+		if group == nil {
+			group = g
+		}
+		if g.Seeds == nil || g.Seeds.PageInfo == nil {
+			return 0
+		}
+		seeds = append(seeds, g.Seeds.Nodes...)
+		return g.Seeds.PageInfo.TotalPages
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = fetchStartGGPages(ctx, c, startGGSetsQuery, phaseGroupID, startGGSetsPerPage, func(g *startGGPhaseGroup) int {
+		if g.Sets == nil || g.Sets.PageInfo == nil {
+			return 0
+		}
+		sets = append(sets, g.Sets.Nodes...)
+		return g.Sets.PageInfo.TotalPages
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	group.Seeds = &startGGSeedConnection{Nodes: seeds}
+	group.Sets = &startGGSetConnection{Nodes: sets}
+	return group, nil
+}
+
+func convertStartGGTime(t *int64) *time.Time {
+	if t == nil {
+		return nil
+	}
+	converted := time.Unix(*t, 0)
+	return &converted
+}
+
+func startGGEntrantID(e *startGGEntrant) string {
+	if e == nil {
+		return "0"
+	}
+	return string(e.ID)
+}
+
+func startGGSlotScore(s *startGGSlot) int {
+	if s.Standing == nil || s.Standing.Stats == nil || s.Standing.Stats.Score == nil || s.Standing.Stats.Score.Value == nil {
+		return 0
+	}
+	return int(*s.Standing.Stats.Score.Value)
+}
+
+func convertStartGGMatch(s *startGGSet) *Match {
+	slot1, slot2 := s.Slots[0], s.Slots[1]
+
+	var prereqs [2]*string
+	for i, slot := range []*startGGSlot{slot1, slot2} {
+		if slot.PrereqID != nil {
+			prereqs[i] = new(string)
+			*prereqs[i] = string(*slot.PrereqID)
+		}
+	}
+
+	player1ID := startGGEntrantID(slot1.Entrant)
+	player2ID := startGGEntrantID(slot2.Entrant)
+	winnerID, loserID := "0", "0"
+	if s.WinnerID != nil {
+		winnerID = strconv.Itoa(*s.WinnerID)
+		if winnerID == player1ID {
+			loserID = player2ID
+		} else {
+			loserID = player1ID
+		}
+	}
+
+	player1Score := startGGSlotScore(slot1)
+	player2Score := startGGSlotScore(slot2)
+
+	m := &Match{
+		ID:                   string(s.ID),
+		Identifier:           s.Identifier,
+		StartedAt:            convertStartGGTime(s.StartedAt),
+		Round:                s.Round,
+		State:                convertSmashGGMatchState(s.State, player1ID != "0" && player2ID != "0"),
+		RawState:             rawSmashGGState(s.State),
+		Player1ID:            player1ID,
+		Player1Score:         player1Score,
+		Player1PrereqMatchID: prereqs[0],
+		Player2ID:            player2ID,
+		Player2Score:         player2Score,
+		Player2PrereqMatchID: prereqs[1],
+		WinnerID:             winnerID,
+		LoserID:              loserID,
+		Games:                convertStartGGGames(s.Games, player1ID, player2ID),
+	}
+	m.Outcome = smashGGOutcome(m, s.Round, slot1.PrereqType, slot2.PrereqType)
+	return m
+}
+
+func convertStartGGGames(data []*startGGGame, player1ID, player2ID string) []Game {
+	var games []Game
+	for _, d := range data {
+		g := Game{Number: d.OrderNum, WinnerID: "0"}
+		if d.WinnerID != nil {
+			g.WinnerID = strconv.Itoa(*d.WinnerID)
+		}
+		if d.Entrant1Score != nil {
+			g.Player1Score = *d.Entrant1Score
+		}
+		if d.Entrant2Score != nil {
+			g.Player2Score = *d.Entrant2Score
+		}
+		if d.Stage != nil {
+			g.Stage = d.Stage.Name
+		}
+		for _, sel := range d.Selections {
+			if sel.Character == nil {
+				continue
+			}
+			switch startGGEntrantID(sel.Entrant) {
+			case player1ID:
+				g.Player1Characters = append(g.Player1Characters, sel.Character.Name)
+			case player2ID:
+				g.Player2Characters = append(g.Player2Characters, sel.Character.Name)
+			}
+		}
+		games = append(games, g)
+	}
+	return games
+}
+
+func convertStartGGMatches(sets []*startGGSet) []*Match {
+	var matches []*Match
+	for _, s := range sets {
+		if len(s.Slots) < 2 {
+			continue
+		}
+		if isSmashGGEmptySet(s.Slots[0].PrereqType, s.Slots[1].PrereqType) {
+			continue
+		}
+		matches = append(matches, convertStartGGMatch(s))
+	}
+	return matches
+}
+
+func convertStartGGPlayers(seeds []*startGGSeed) []*Player {
+	var players []*Player
+	for _, s := range seeds {
+		if s.Entrant == nil {
+			continue
+		}
+		player := &Player{
+			ID:   string(s.Entrant.ID),
+			Name: s.Entrant.Name,
+			Seed: s.SeedNum,
+			Rank: s.Placement,
+		}
+		if len(s.Entrant.Participants) == 1 {
+			addStartGGIdentity(player, s.Entrant.Participants[0])
+		} else {
+			for _, p := range s.Entrant.Participants {
+				member := &Player{ID: string(p.ID)}
+				addStartGGIdentity(member, p)
+				member.Name = sponsoredName(member.Prefix, member.GamerTag)
+				player.Members = append(player.Members, member)
+			}
+		}
+		players = append(players, player)
+	}
+	return players
+}
+
+// addStartGGIdentity fills in who a player is from their participant
+// record. The user's name and location are only set if they've made them
+// public.
+func addStartGGIdentity(player *Player, p *startGGParticipant) {
+	player.GamerTag = p.GamerTag
+	player.Prefix = p.Prefix
+	if p.Player != nil {
+		player.AccountID = string(p.Player.ID)
+	}
+	if p.User != nil {
+		player.RealName = p.User.Name
+		if p.User.Location != nil {
+			player.Country = p.User.Location.Country
+			player.Region = p.User.Location.State
+		}
+	}
+}
+
+func convertStartGGData(group *startGGPhaseGroup) *Bracket {
+	b := &Bracket{
+		State:    convertSmashGGBracketState(group.State),
+		RawState: rawSmashGGState(group.State),
+		Players:  convertStartGGPlayers(group.Seeds.Nodes),
+		Matches:  convertStartGGMatches(group.Sets.Nodes),
+	}
+	if ph := group.Phase; ph != nil && ph.Event != nil {
+		b.Phase = &Phase{ID: string(ph.ID), Name: ph.Name, Order: ph.PhaseOrder}
+		b.Event = convertStartGGEvent(ph.Event)
+		tournamentName := ""
+		if ph.Event.Tournament != nil {
+			b.Tournament = convertStartGGTournament(ph.Event.Tournament)
+			tournamentName = b.Tournament.Name
+		}
+		b.Name = bracketName(tournamentName, ph.Event.Name, ph.Name, group.DisplayIdentifier, ph.GroupCount)
+		b.URL = startGGBracketURL(ph.Event.Slug, string(ph.ID), string(group.ID))
+	}
+	b.Format = convertStartGGFormat(group.BracketType)
+	assignSides(b.Matches, b.Format)
+	b.FormatSettings = smashGGFormatSettings(b.Format, b.Matches)
+
+	// start.gg does not return a start time on the phase group, so
+	// take the earliest one from the matches
+	for _, m := range b.Matches {
+		if m.StartedAt != nil && (b.StartedAt == nil || m.StartedAt.Before(*b.StartedAt)) {
+			b.StartedAt = m.StartedAt
+		}
+	}
+
+	return b
+}
+
+func fetchStartGGBracket(ctx context.Context, c *Client, url string) (*Bracket, error) {
+	phaseGroupID, err := getStartGGPhaseGroupID(url)
+	if err != nil {
+		return nil, err
+	}
+	group, err := fetchStartGGData(ctx, c, phaseGroupID)
+	if err != nil {
+		return nil, err
+	}
+
+	b := convertStartGGData(group)
+	if b.URL == "" {
+		b.URL = url
+	}
+	return b, nil
+}
@@ -0,0 +1,285 @@
+package bracket
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newStartGGTestServer serves GraphQL requests by passing the operation
+// name and variables to respond, which returns the response body.
+func newStartGGTestServer(t *testing.T, respond func(operation string, vars map[string]interface{}) string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method)
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+
+		var req startGGRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatal(err)
+		}
+		operation := strings.Fields(req.Query)[1]
+		operation = operation[:strings.Index(operation, "(")]
+		w.Write([]byte(respond(operation, req.Variables)))
+	}))
+}
+
+func readStartGGFixture(t *testing.T, name string) string {
+	b, err := ioutil.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestStartGGMatchURL(t *testing.T) {
+	startGGURL := "https://www.start.gg/tournament/super-smash-sundays-48/event/melee-singles/brackets/50133/165583"
+	smashGGURL := "https://smash.gg/tournament/super-smash-sundays-48/brackets/14221/50133/165583"
+
+	c := New()
+	assert.Equal(t, "startgg", c.ProviderFor(startGGURL).Name())
+	assert.Equal(t, "smashgg", c.ProviderFor(smashGGURL).Name())
+
+	c = New(WithStartGGToken("token"))
+	assert.Equal(t, "startgg", c.ProviderFor(startGGURL).Name())
+	assert.Equal(t, "startgg", c.ProviderFor(smashGGURL).Name())
+}
+
+func TestGetStartGGPhaseGroupID(t *testing.T) {
+	id, err := getStartGGPhaseGroupID("https://www.start.gg/tournament/super-smash-sundays-48/event/melee-singles/brackets/50133/165583/")
+	assert.NoError(t, err)
+	assert.Equal(t, "165583", id)
+
+	id, err = getStartGGPhaseGroupID("https://smash.gg/tournament/super-smash-sundays-48/brackets/14221/50133/165583")
+	assert.NoError(t, err)
+	assert.Equal(t, "165583", id)
+
+	_, err = getStartGGPhaseGroupID("https://www.start.gg/tournament/super-smash-sundays-48/details")
+	assert.True(t, errors.Is(err, ErrUnsupportedURL))
+}
+
+func TestStartGGID(t *testing.T) {
+	var ids []startGGID
+	err := json.Unmarshal([]byte(`[4689059, "preview_171722_1_0"]`), &ids)
+	assert.NoError(t, err)
+	assert.Equal(t, []startGGID{"4689059", "preview_171722_1_0"}, ids)
+}
+
+func TestFetchStartGGBracket(t *testing.T) {
+	server := newStartGGTestServer(t, func(operation string, vars map[string]interface{}) string {
+		assert.Equal(t, "171722", vars["id"])
+		switch operation {
+		case "PhaseGroupSeeds":
+			return readStartGGFixture(t, "startgg_seeds.json")
+		case "PhaseGroupSets":
+			return readStartGGFixture(t, fmt.Sprintf("startgg_sets_%v.json", vars["page"]))
+		}
+		t.Fatalf("unexpected operation %s", operation)
+		return ""
+	})
+	defer server.Close()
+
+	c := New(WithStartGGToken("token"), WithStartGGEndpoint(server.URL))
+	url := "https://www.start.gg/tournament/super-smash-sundays-48/event/melee-singles/brackets/50132/171722"
+	bracket, err := c.FetchBracket(url)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, url, bracket.URL)
+	assert.Equal(t, "Super Smash Sundays 48 – Melee Singles – Pools A3", bracket.Name)
+	assert.Equal(t, "Esports Arena", bracket.Tournament.Venue)
+	assert.Equal(t, "America/Los_Angeles", bracket.Tournament.Timezone)
+	assert.Equal(t, int64(1468180800), bracket.Tournament.StartAt.Unix())
+	assert.Equal(t, "https://www.start.gg/tournament/super-smash-sundays-48/event/melee-singles", bracket.Event.URL)
+	assert.Equal(t, &Phase{ID: "50132", Name: "Pools", Order: 1}, bracket.Phase)
+	assert.Equal(t, BracketStateComplete, bracket.State)
+	startedAt := time.Unix(1468186500, 0)
+	assert.Equal(t, &startedAt, bracket.StartedAt)
+
+	// Players
+	players := bracket.Players
+	assert.Len(t, players, 3)
+	player := players[0]
+	assert.Equal(t, "211768", player.ID)
+	assert.Equal(t, "TA | CDK", player.Name)
+	assert.Equal(t, 3, player.Rank)
+	assert.Equal(t, 7, player.Seed)
+	assert.Equal(t, "CDK", player.GamerTag)
+	assert.Equal(t, "TA", player.Prefix)
+	assert.Equal(t, "Connor Nguyen", player.RealName)
+	assert.Equal(t, "US", player.Country)
+	assert.Equal(t, "CA", player.Region)
+	assert.Equal(t, "1092", player.AccountID)
+	player = players[2]
+	assert.Equal(t, "212928", player.ID)
+	assert.Equal(t, "Slime", player.Name)
+	assert.Equal(t, 9, player.Rank)
+	assert.Equal(t, 122, player.Seed)
+
+	// Matches, including the round 1 bye
+	matches := bracket.Matches
+	assert.Len(t, matches, 3)
+	match := matches[0]
+	assert.Equal(t, "4689059", match.ID)
+	assert.Equal(t, "A", match.Identifier)
+	assert.Equal(t, 1, match.Round)
+	assert.Equal(t, MatchStateComplete, match.State)
+	assert.Equal(t, "211768", match.Player1ID)
+	assert.Equal(t, 2, match.Player1Score)
+	assert.Equal(t, "2426316", *match.Player1PrereqMatchID)
+	assert.Equal(t, "212928", match.Player2ID)
+	assert.Equal(t, 0, match.Player2Score)
+	assert.Equal(t, "211768", match.WinnerID)
+	assert.Equal(t, "212928", match.LoserID)
+	assert.Equal(t, OutcomePlayed, match.Outcome)
+	match = matches[1]
+	assert.Equal(t, "4689060", match.ID)
+	assert.Equal(t, OutcomeBye, match.Outcome)
+	match = matches[2]
+	assert.Equal(t, "4689067", match.ID)
+	assert.Equal(t, "I", match.Identifier)
+	assert.Equal(t, 2, match.Round)
+	assert.Equal(t, &startedAt, match.StartedAt)
+	assert.Equal(t, "4689059", *match.Player1PrereqMatchID)
+	assert.Equal(t, "4689060", *match.Player2PrereqMatchID)
+	assert.Equal(t, "211768", match.WinnerID)
+	assert.Equal(t, "211974", match.LoserID)
+}
+
+func TestFetchStartGGBracketSmashGGURL(t *testing.T) {
+	server := newStartGGTestServer(t, func(operation string, vars map[string]interface{}) string {
+		switch operation {
+		case "PhaseGroupSeeds":
+			return readStartGGFixture(t, "startgg_seeds.json")
+		case "PhaseGroupSets":
+			return readStartGGFixture(t, fmt.Sprintf("startgg_sets_%v.json", vars["page"]))
+		}
+		t.Fatalf("unexpected operation %s", operation)
+		return ""
+	})
+	defer server.Close()
+
+	c := New(WithStartGGToken("token"), WithStartGGEndpoint(server.URL))
+	bracket, err := c.FetchBracket("https://smash.gg/tournament/super-smash-sundays-48/events/melee-singles/brackets/50132/171722/")
+	if !assert.NoError(t, err) {
+		return
+	}
+	// old smash.gg URLs are replaced with the bracket's start.gg URL
+	assert.Equal(t, "https://www.start.gg/tournament/super-smash-sundays-48/event/melee-singles/brackets/50132/171722", bracket.URL)
+	assert.Equal(t, "Super Smash Sundays 48 – Melee Singles – Pools A3", bracket.Name)
+}
+
+func TestStartGGComplexityLimit(t *testing.T) {
+	const totalSets = 40
+	var perPages []float64
+	server := newStartGGTestServer(t, func(operation string, vars map[string]interface{}) string {
+		if operation == "PhaseGroupSeeds" {
+			return `{"data":{"phaseGroup":{"id":1,"state":1,"seeds":{"pageInfo":{"total":0,"totalPages":0},"nodes":[]}}}}`
+		}
+		page, perPage := vars["page"].(float64), vars["perPage"].(float64)
+		perPages = append(perPages, perPage)
+		if perPage > 8 {
+			return `{"errors":[{"message":"Your query complexity is too high. A maximum of 1000 objects may be returned by each request."}]}`
+		}
+		var nodes []string
+		for i := int(page-1) * int(perPage); i < int(page*perPage) && i < totalSets; i++ {
+			nodes = append(nodes, fmt.Sprintf(`{"id":%d,"round":2,"state":1,"slots":[{},{}]}`, i))
+		}
+		totalPages := (totalSets + int(perPage) - 1) / int(perPage)
+		return fmt.Sprintf(`{"data":{"phaseGroup":{"id":1,"sets":{"pageInfo":{"total":%d,"totalPages":%d},"nodes":[%s]}}}}`,
+			totalSets, totalPages, strings.Join(nodes, ","))
+	})
+	defer server.Close()
+
+	c := New(WithStartGGToken("token"), WithStartGGEndpoint(server.URL))
+	bracket, err := c.FetchBracket("https://www.start.gg/tournament/x/event/y/brackets/1/1")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Len(t, bracket.Matches, totalSets)
+	for i, m := range bracket.Matches {
+		assert.Equal(t, fmt.Sprint(i), m.ID)
+	}
+	assert.Equal(t, []float64{32, 16, 8, 8, 8, 8, 8}, perPages)
+}
+
+func TestStartGGErrors(t *testing.T) {
+	server := newStartGGTestServer(t, func(operation string, vars map[string]interface{}) string {
+		return `{"data":{"phaseGroup":null}}`
+	})
+	defer server.Close()
+
+	c := New(WithStartGGToken("token"), WithStartGGEndpoint(server.URL))
+	_, err := c.FetchBracket("https://www.start.gg/tournament/x/event/y/brackets/1/1")
+	assert.True(t, errors.Is(err, ErrNotFound))
+
+	body := []byte(`{"success":false,"message":"Invalid authentication token"}`)
+	assert.Equal(t, []string{"Invalid authentication token"}, decodeStartGGErrors(body))
+}
+
+func TestConvertStartGGGames(t *testing.T) {
+	var set startGGSet
+	err := json.Unmarshal([]byte(`{
+		"id": 1,
+		"winnerId": 10,
+		"slots": [{"entrant": {"id": 10}}, {"entrant": {"id": 20}}],
+		"games": [{
+			"orderNum": 1,
+			"winnerId": 10,
+			"entrant1Score": 3,
+			"entrant2Score": 0,
+			"stage": {"name": "Battlefield"},
+			"selections": [
+				{"entrant": {"id": 20}, "character": {"name": "Fox"}},
+				{"entrant": {"id": 10}, "character": {"name": "Marth"}}
+			]
+		}, {
+			"orderNum": 2,
+			"winnerId": null,
+			"entrant1Score": null,
+			"entrant2Score": null,
+			"stage": null,
+			"selections": null
+		}]
+	}`), &set)
+	assert.NoError(t, err)
+
+	match := convertStartGGMatch(&set)
+	assert.Equal(t, []Game{
+		{
+			Number:            1,
+			WinnerID:          "10",
+			Player1Score:      3,
+			Player1Characters: []string{"Marth"},
+			Player2Characters: []string{"Fox"},
+			Stage:             "Battlefield",
+		},
+		{Number: 2, WinnerID: "0"},
+	}, match.Games)
+}
+
+func TestConvertStartGGTeamPlayers(t *testing.T) {
+	var seeds []*startGGSeed
+	err := json.Unmarshal([]byte(`[{"seedNum":1,"placement":2,"entrant":{"id":300,"name":"TA | CDK / Slime","participants":[
+		{"id":238181,"gamerTag":"CDK","prefix":"TA","player":{"id":1092}},
+		{"id":239067,"gamerTag":"Slime","prefix":"","player":{"id":13963},"user":{"name":"Anthony Bruno","location":{"country":"US","state":"CA"}}}]}}]`), &seeds)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	players := convertStartGGPlayers(seeds)
+	assert.Len(t, players, 1)
+	assert.Equal(t, "", players[0].AccountID)
+	assert.Equal(t, []*Player{
+		{ID: "238181", Name: "TA | CDK", GamerTag: "CDK", Prefix: "TA", AccountID: "1092"},
+		{ID: "239067", Name: "Slime", GamerTag: "Slime", RealName: "Anthony Bruno", Country: "US", Region: "CA", AccountID: "13963"},
+	}, players[0].Members)
+}
@@ -0,0 +1,248 @@
+package bracket
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+const startGGURL = "https://www.start.gg/"
+
+// start.gg pages phase groups like seeds, so large phases such as a
+// major's pools take more than one request.
+const startGGPhaseGroupsPerPage = 64
+
+// startGGTournamentFields selects a tournament's own details.
+const startGGTournamentFields = `
+          id
+          name
+          slug
+          startAt
+          endAt
+          venueName
+          venueAddress
+          timezone
+`
+
+const startGGTournamentQuery = `query TournamentEvents($slug: String!) {
+  tournament(slug: $slug) {` + startGGTournamentFields + `
+    events { id name slug }
+  }
+}`
+
+const startGGEventQuery = `query EventPhases($slug: String!) {
+  event(slug: $slug) {
+    id
+    name
+    slug
+    phases { id name phaseOrder }
+  }
+}`
+
+const startGGPhaseGroupsQuery = `query PhaseGroups($id: ID!, $page: Int!, $perPage: Int!) {
+  phase(id: $id) {
+    phaseGroups(query: {page: $page, perPage: $perPage}) {
+      pageInfo { total totalPages }
+      nodes { id displayIdentifier }
+    }
+  }
+}`
+
+type startGGTournamentData struct {
+	Tournament *startGGTournament `json:"tournament"`
+}
+
+type startGGTournament struct {
+	ID           startGGID       `json:"id"`
+	Name         string          `json:"name"`
+	Slug         string          `json:"slug"`
+	StartAt      *int64          `json:"startAt"`
+	EndAt        *int64          `json:"endAt"`
+	VenueName    string          `json:"venueName"`
+	VenueAddress string          `json:"venueAddress"`
+	Timezone     string          `json:"timezone"`
+	Events       []*startGGEvent `json:"events"`
+}
+
+type startGGEventData struct {
+	Event *startGGEvent `json:"event"`
+}
+
+type startGGEvent struct {
+	ID         startGGID            `json:"id"`
+	Name       string               `json:"name"`
+	Slug       string               `json:"slug"`
+	Tournament *startGGTournament   `json:"tournament"`
+	Phases     []*startGGEventPhase `json:"phases"`
+}
+
+type startGGEventPhase struct {
+	ID         startGGID `json:"id"`
+	Name       string    `json:"name"`
+	PhaseOrder int       `json:"phaseOrder"`
+}
+
+type startGGPhaseData struct {
+	Phase *struct {
+		PhaseGroups *startGGPhaseGroupConnection `json:"phaseGroups"`
+	} `json:"phase"`
+}
+
+type startGGPhaseGroupConnection struct {
+	PageInfo *startGGPageInfo     `json:"pageInfo"`
+	Nodes    []*startGGPhaseGroup `json:"nodes"`
+}
+
+// getStartGGSlugs pulls the tournament and event slugs out of a URL such
+// as https://www.start.gg/tournament/x/event/y/brackets/50133/165583. The
+// event slug is empty if the URL doesn't link to an event.
+func getStartGGSlugs(url string) (string, string, error) {
+	segments := strings.Split(strings.TrimRight(url, "/"), "/")
+	tournament, event := "", ""
+	for i := 0; i+1 < len(segments); i++ {
+		switch segments[i] {
+		case "tournament":
+			tournament = segments[i+1]
+		case "event", "events":
+			if tournament != "" {
+				event = segments[i+1]
+			}
+		}
+	}
+	if tournament == "" {
+		return "", "", fmt.Errorf("%w: %s does not link to a tournament", ErrUnsupportedURL, url)
+	}
+	return tournament, event, nil
+}
+
+// startGGBracketURL builds the canonical URL of a phase group from its
+// event's slug, such as "tournament/x/event/y".
+func startGGBracketURL(eventSlug, phaseID, phaseGroupID string) string {
+	return startGGURL + eventSlug + "/brackets/" + phaseID + "/" + phaseGroupID
+}
+
+// convertStartGGTournament converts a tournament's own details, leaving
+// out its events.
+func convertStartGGTournament(t *startGGTournament) *Tournament {
+	return &Tournament{
+		ID:           string(t.ID),
+		Name:         t.Name,
+		URL:          startGGURL + t.Slug,
+		StartAt:      convertStartGGTime(t.StartAt),
+		EndAt:        convertStartGGTime(t.EndAt),
+		Venue:        t.VenueName,
+		VenueAddress: t.VenueAddress,
+		Timezone:     t.Timezone,
+	}
+}
+
+// convertStartGGEvent converts an event's own details, leaving out its
+// phases.
+func convertStartGGEvent(e *startGGEvent) *TournamentEvent {
+	return &TournamentEvent{
+		ID:   string(e.ID),
+		Name: e.Name,
+		URL:  startGGURL + e.Slug,
+	}
+}
+
+// FetchTournament implements TournamentFetcher.
+func (p *startGGProvider) FetchTournament(ctx context.Context, url string) (*Tournament, error) {
+	slug, _, err := getStartGGSlugs(url)
+	if err != nil {
+		return nil, err
+	}
+	var data startGGTournamentData
+	if err := startGGQuery(ctx, p.client, startGGTournamentQuery, map[string]interface{}{"slug": slug}, &data); err != nil {
+		return nil, err
+	}
+	if data.Tournament == nil {
+		return nil, fmt.Errorf("%w: start.gg tournament %s", ErrNotFound, slug)
+	}
+
+	t := convertStartGGTournament(data.Tournament)
+	for _, e := range data.Tournament.Events {
+		event, err := fetchStartGGEvent(ctx, p.client, e.Slug)
+		if err != nil {
+			return nil, err
+		}
+		t.Events = append(t.Events, event)
+	}
+	return t, nil
+}
+
+// FetchTournamentEvent implements TournamentFetcher.
+func (p *startGGProvider) FetchTournamentEvent(ctx context.Context, url string) (*TournamentEvent, error) {
+	tournament, event, err := getStartGGSlugs(url)
+	if err != nil {
+		return nil, err
+	}
+	if event == "" {
+		return nil, fmt.Errorf("%w: %s does not link to an event", ErrUnsupportedURL, url)
+	}
+	return fetchStartGGEvent(ctx, p.client, "tournament/"+tournament+"/event/"+event)
+}
+
+// fetchStartGGEvent fetches an event's phases and phase groups, then the
+// phase groups' brackets all at once.
+func fetchStartGGEvent(ctx context.Context, c *Client, slug string) (*TournamentEvent, error) {
+	var data startGGEventData
+	if err := startGGQuery(ctx, c, startGGEventQuery, map[string]interface{}{"slug": slug}, &data); err != nil {
+		return nil, err
+	}
+	if data.Event == nil {
+		return nil, fmt.Errorf("%w: start.gg event %s", ErrNotFound, slug)
+	}
+
+	e := convertStartGGEvent(data.Event)
+	var groups []*PhaseGroup
+	var urls []string
+	for _, ph := range data.Event.Phases {
+		phase := &Phase{ID: string(ph.ID), Name: ph.Name, Order: ph.PhaseOrder}
+		nodes, err := fetchStartGGPhaseGroups(ctx, c, phase.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, n := range nodes {
+			g := &PhaseGroup{
+				ID:         string(n.ID),
+				Identifier: n.DisplayIdentifier,
+				URL:        startGGBracketURL(data.Event.Slug, phase.ID, string(n.ID)),
+			}
+			phase.Groups = append(phase.Groups, g)
+			groups = append(groups, g)
+			urls = append(urls, g.URL)
+		}
+		e.Phases = append(e.Phases, phase)
+	}
+
+	for i, r := range c.FetchBrackets(ctx, urls, nil) {
+		if r.Err != nil {
+			return nil, r.Err
+		}
+		groups[i].Bracket = r.Bracket
+	}
+	return e, nil
+}
+
+func fetchStartGGPhaseGroups(ctx context.Context, c *Client, phaseID string) ([]*startGGPhaseGroup, error) {
+	var groups []*startGGPhaseGroup
+	for page := 1; ; page++ {
+		var data startGGPhaseData
+		err := startGGQuery(ctx, c, startGGPhaseGroupsQuery, map[string]interface{}{
+			"id":      phaseID,
+			"page":    page,
+			"perPage": startGGPhaseGroupsPerPage,
+		}, &data)
+		if err != nil {
+			return nil, err
+		}
+		if data.Phase == nil || data.Phase.PhaseGroups == nil {
+			return nil, fmt.Errorf("%w: start.gg phase %s", ErrNotFound, phaseID)
+		}
+		groups = append(groups, data.Phase.PhaseGroups.Nodes...)
+		if pageInfo := data.Phase.PhaseGroups.PageInfo; pageInfo == nil || page >= pageInfo.TotalPages {
+			return groups, nil
+		}
+	}
+}
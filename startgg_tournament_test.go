@@ -0,0 +1,118 @@
+package bracket
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetStartGGSlugs(t *testing.T) {
+	tests := []struct {
+		url, tournament, event string
+	}{
+		{"https://www.start.gg/tournament/genesis-4/event/melee-singles/brackets/1/2", "genesis-4", "melee-singles"},
+		{"https://smash.gg/tournament/genesis-4/events/melee-singles/", "genesis-4", "melee-singles"},
+		{"https://smash.gg/tournament/super-smash-sundays-48/brackets/14221/50133/165583", "super-smash-sundays-48", ""},
+		{"https://www.start.gg/tournament/genesis-4", "genesis-4", ""},
+	}
+	for _, tt := range tests {
+		tournament, event, err := getStartGGSlugs(tt.url)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.tournament, tournament, tt.url)
+		assert.Equal(t, tt.event, event, tt.url)
+	}
+
+	_, _, err := getStartGGSlugs("https://www.start.gg/user/1234")
+	assert.True(t, errors.Is(err, ErrUnsupportedURL))
+}
+
+// startGGPhaseGroupResponse builds a one page phase group with two players
+// and a match between them.
+func startGGPhaseGroupResponse(operation string, groupID string, entrants ...int) string {
+	switch operation {
+	case "PhaseGroupSeeds":
+		return fmt.Sprintf(`{"data":{"phaseGroup":{"id":%s,"state":3,"bracketType":"DOUBLE_ELIMINATION","seeds":{"pageInfo":{"total":2,"totalPages":1},"nodes":[
+			{"id":1,"seedNum":1,"placement":1,"entrant":{"id":%d,"name":"P%d"}},
+			{"id":2,"seedNum":2,"placement":2,"entrant":{"id":%d,"name":"P%d"}}]}}}}`,
+			groupID, entrants[0], entrants[0], entrants[1], entrants[1])
+	case "PhaseGroupSets":
+		return fmt.Sprintf(`{"data":{"phaseGroup":{"id":%s,"sets":{"pageInfo":{"total":1,"totalPages":1},"nodes":[
+			{"id":%s1,"round":1,"state":3,"winnerId":%d,"slots":[{"entrant":{"id":%d}},{"entrant":{"id":%d}}]}]}}}}`,
+			groupID, groupID, entrants[0], entrants[0], entrants[1])
+	}
+	return ""
+}
+
+func TestFetchStartGGTournament(t *testing.T) {
+	server := newStartGGTestServer(t, func(operation string, vars map[string]interface{}) string {
+		switch operation {
+		case "TournamentEvents":
+			assert.Equal(t, "genesis-4", vars["slug"])
+			return `{"data":{"tournament":{"id":1,"name":"Genesis 4","slug":"tournament/genesis-4","startAt":1484956800,"endAt":1485129600,
+				"events":[{"id":10,"name":"Melee Singles","slug":"tournament/genesis-4/event/melee-singles"}]}}}`
+		case "EventPhases":
+			assert.Equal(t, "tournament/genesis-4/event/melee-singles", vars["slug"])
+			return `{"data":{"event":{"id":10,"name":"Melee Singles","slug":"tournament/genesis-4/event/melee-singles",
+				"phases":[{"id":100,"name":"Pools","phaseOrder":1},{"id":200,"name":"Top 8","phaseOrder":2}]}}}`
+		case "PhaseGroups":
+			if vars["id"] == "100" {
+				return `{"data":{"phase":{"phaseGroups":{"pageInfo":{"total":2,"totalPages":1},"nodes":[{"id":1001,"displayIdentifier":"A1"},{"id":1002,"displayIdentifier":"A2"}]}}}}`
+			}
+			return `{"data":{"phase":{"phaseGroups":{"pageInfo":{"total":1,"totalPages":1},"nodes":[{"id":2001,"displayIdentifier":"1"}]}}}}`
+		}
+		switch vars["id"] {
+		case "1001":
+			return startGGPhaseGroupResponse(operation, "1001", 1, 2)
+		case "1002":
+			return startGGPhaseGroupResponse(operation, "1002", 3, 4)
+		case "2001":
+			return startGGPhaseGroupResponse(operation, "2001", 1, 3)
+		}
+		t.Fatalf("unexpected %s %v", operation, vars)
+		return ""
+	})
+	defer server.Close()
+
+	c := New(WithStartGGToken("token"), WithStartGGEndpoint(server.URL))
+	tournament, err := c.FetchTournament(context.Background(), "https://www.start.gg/tournament/genesis-4/details")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "Genesis 4", tournament.Name)
+	assert.Equal(t, "https://www.start.gg/tournament/genesis-4", tournament.URL)
+	assert.Equal(t, int64(1484956800), tournament.StartAt.Unix())
+	assert.Len(t, tournament.Events, 1)
+
+	event := tournament.Events[0]
+	assert.Equal(t, "Melee Singles", event.Name)
+	assert.Len(t, event.Phases, 2)
+	pools := event.Phases[0]
+	assert.Equal(t, "Pools", pools.Name)
+	assert.Equal(t, 1, pools.Order)
+	assert.Len(t, pools.Groups, 2)
+	assert.Equal(t, "A2", pools.Groups[1].Identifier)
+	assert.Equal(t, "https://www.start.gg/tournament/genesis-4/event/melee-singles/brackets/100/1002", pools.Groups[1].URL)
+	assert.Equal(t, "3", pools.Groups[1].Bracket.Players[0].ID)
+
+	b := event.Bracket()
+	assert.Len(t, b.Players, 4)
+	assert.Len(t, b.Matches, 3)
+	assert.Equal(t, BracketStateComplete, b.State)
+	// P1 won pool A1 and top 8
+	assert.Equal(t, &Player{ID: "1", Name: "P1", Seed: 1, Rank: 1}, b.Players[0])
+	// P2 came second in pool A1 but has no rank, since ranks only come
+	// from top 8
+	assert.Equal(t, &Player{ID: "2", Name: "P2", Seed: 2}, b.Players[1])
+	// P3 won pool A2 and came second in top 8
+	assert.Equal(t, &Player{ID: "3", Name: "P3", Seed: 1, Rank: 2}, b.Players[2])
+
+	event, err = c.FetchTournamentEvent(context.Background(), "https://www.start.gg/tournament/genesis-4/event/melee-singles/overview")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://www.start.gg/tournament/genesis-4/event/melee-singles", event.URL)
+
+	_, err = c.FetchTournamentEvent(context.Background(), "https://www.start.gg/tournament/genesis-4")
+	assert.True(t, errors.Is(err, ErrUnsupportedURL))
+}
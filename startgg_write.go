@@ -0,0 +1,145 @@
+package bracket
+
+import (
+	"context"
+	"fmt"
+)
+
+const startGGSetSlotsQuery = `query SetSlots($id: ID!) {
+  set(id: $id) {
+    id
+    slots { entrant { id } }
+  }
+}`
+
+const startGGReportSetMutation = `mutation ReportSet($setId: ID!, $winnerId: ID, $isDQ: Boolean, $gameData: [BracketSetGameDataInput]) {
+  reportBracketSet(setId: $setId, winnerId: $winnerId, isDQ: $isDQ, gameData: $gameData) {` + startGGSetFields + `}
+}`
+
+const startGGUpdateSetMutation = `mutation UpdateSet($setId: ID!, $gameData: [BracketSetGameDataInput]) {
+  updateBracketSet(setId: $setId, gameData: $gameData) {` + startGGSetFields + `}
+}`
+
+const startGGMarkSetInProgressMutation = `mutation MarkSetInProgress($setId: ID!) {
+  markSetInProgress(setId: $setId) {` + startGGSetFields + `}
+}`
+
+const startGGResetSetMutation = `mutation ResetSet($setId: ID!) {
+  resetSet(setId: $setId, resetDependentSets: true) {` + startGGSetFields + `}
+}`
+
+type startGGSetData struct {
+	Set *startGGSet `json:"set"`
+}
+
+type startGGGameData struct {
+	GameNum       int    `json:"gameNum"`
+	WinnerID      string `json:"winnerId,omitempty"`
+	Entrant1Score int    `json:"entrant1Score"`
+	Entrant2Score int    `json:"entrant2Score"`
+}
+
+// fetchStartGGSetEntrants returns the entrant IDs in each slot of a set,
+// which start.gg needs to know the winner of each game.
+func fetchStartGGSetEntrants(ctx context.Context, c *Client, setID string) (string, string, error) {
+	var data startGGSetData
+	err := startGGQuery(ctx, c, startGGSetSlotsQuery, map[string]interface{}{"id": setID}, &data)
+	if err != nil {
+		return "", "", err
+	}
+	if data.Set == nil || len(data.Set.Slots) < 2 {
+		return "", "", fmt.Errorf("%w: start.gg set %s", ErrNotFound, setID)
+	}
+	return startGGEntrantID(data.Set.Slots[0].Entrant), startGGEntrantID(data.Set.Slots[1].Entrant), nil
+}
+
+func convertStartGGGameData(scores []GameScore, entrant1ID, entrant2ID string) []*startGGGameData {
+	games := make([]*startGGGameData, len(scores))
+	for i, s := range scores {
+		games[i] = &startGGGameData{
+			GameNum:       i + 1,
+			Entrant1Score: s.Player1Score,
+			Entrant2Score: s.Player2Score,
+		}
+		if s.Player1Score > s.Player2Score {
+			games[i].WinnerID = entrant1ID
+		} else if s.Player2Score > s.Player1Score {
+			games[i].WinnerID = entrant2ID
+		}
+	}
+	return games
+}
+
+// startGGSetMatch converts a set returned by a mutation into a match.
+func startGGSetMatch(s *startGGSet, setID string) (*Match, error) {
+	if s == nil || len(s.Slots) < 2 {
+		return nil, fmt.Errorf("%w: start.gg set %s", ErrNotFound, setID)
+	}
+	return convertStartGGMatch(s), nil
+}
+
+// ReportMatch implements MatchReporter using start.gg's reportBracketSet
+// mutation, or updateBracketSet when there's no winner yet.
+func (p *startGGProvider) ReportMatch(ctx context.Context, url, matchID string, r *MatchReport) (*Match, error) {
+	if err := r.validate(); err != nil {
+		return nil, err
+	}
+	variables := map[string]interface{}{"setId": matchID}
+	if len(r.Scores) > 0 {
+		entrant1ID, entrant2ID, err := fetchStartGGSetEntrants(ctx, p.client, matchID)
+		if err != nil {
+			return nil, err
+		}
+		variables["gameData"] = convertStartGGGameData(r.Scores, entrant1ID, entrant2ID)
+	}
+
+	if r.WinnerID == "" {
+		var data struct {
+			Set *startGGSet `json:"updateBracketSet"`
+		}
+		if err := startGGMutate(ctx, p.client, startGGUpdateSetMutation, variables, &data); err != nil {
+			return nil, err
+		}
+		return startGGSetMatch(data.Set, matchID)
+	}
+
+	variables["winnerId"] = r.WinnerID
+	variables["isDQ"] = r.Disqualified
+	var data struct {
+		Sets []*startGGSet `json:"reportBracketSet"`
+	}
+	if err := startGGMutate(ctx, p.client, startGGReportSetMutation, variables, &data); err != nil {
+		return nil, err
+	}
+	// reportBracketSet also returns the sets the players moved into
+	for _, s := range data.Sets {
+		if string(s.ID) == matchID {
+			return startGGSetMatch(s, matchID)
+		}
+	}
+	return nil, fmt.Errorf("%w: start.gg set %s", ErrNotFound, matchID)
+}
+
+// MarkMatchInProgress implements MatchUpdater.
+func (p *startGGProvider) MarkMatchInProgress(ctx context.Context, url, matchID string) (*Match, error) {
+	var data struct {
+		Set *startGGSet `json:"markSetInProgress"`
+	}
+	err := startGGMutate(ctx, p.client, startGGMarkSetInProgressMutation, map[string]interface{}{"setId": matchID}, &data)
+	if err != nil {
+		return nil, err
+	}
+	return startGGSetMatch(data.Set, matchID)
+}
+
+// ResetMatch implements MatchUpdater.
+func (p *startGGProvider) ResetMatch(ctx context.Context, url, matchID string) (*Match, error) {
+	var data struct {
+		Set *startGGSet `json:"resetSet"`
+	}
+	err := startGGMutate(ctx, p.client, startGGResetSetMutation, map[string]interface{}{"setId": matchID}, &data)
+	if err != nil {
+		return nil, err
+	}
+	return startGGSetMatch(data.Set, matchID)
+}
@@ -0,0 +1,147 @@
+package bracket
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const startGGMutationSet = `{
+  "id": 4689059,
+  "identifier": "A",
+  "round": 1,
+  "state": 3,
+  "startedAt": 1507140000,
+  "winnerId": 1092521,
+  "slots": [
+    {"prereqType": "seed", "prereqId": 1, "entrant": {"id": 1092521}, "standing": {"stats": {"score": {"value": 2}}}},
+    {"prereqType": "seed", "prereqId": 2, "entrant": {"id": 1092522}, "standing": {"stats": {"score": {"value": 1}}}}
+  ]
+}`
+
+func TestReportStartGGMatch(t *testing.T) {
+	var operations []string
+	var gameData interface{}
+	server := newStartGGTestServer(t, func(operation string, vars map[string]interface{}) string {
+		operations = append(operations, operation)
+		switch operation {
+		case "SetSlots":
+			assert.Equal(t, "4689059", vars["id"])
+			return `{"data":{"set":{"id":4689059,"slots":[{"entrant":{"id":1092521}},{"entrant":{"id":1092522}}]}}}`
+		case "ReportSet":
+			assert.Equal(t, "4689059", vars["setId"])
+			assert.Equal(t, "1092521", vars["winnerId"])
+			assert.Equal(t, false, vars["isDQ"])
+			gameData = vars["gameData"]
+			return `{"data":{"reportBracketSet":[{"id":4689070,"slots":[]},` + startGGMutationSet + `]}}`
+		}
+		t.Fatalf("unexpected operation %s", operation)
+		return ""
+	})
+	defer server.Close()
+
+	c := New(WithStartGGToken("token"), WithStartGGEndpoint(server.URL))
+	m, err := c.ReportMatch(context.Background(), "https://smash.gg/tournament/x/brackets/1/2/171722", "4689059", &MatchReport{
+		WinnerID: "1092521",
+		Scores:   []GameScore{{1, 0}, {0, 1}, {1, 0}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{"SetSlots", "ReportSet"}, operations)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"gameNum": 1.0, "winnerId": "1092521", "entrant1Score": 1.0, "entrant2Score": 0.0},
+		map[string]interface{}{"gameNum": 2.0, "winnerId": "1092522", "entrant1Score": 0.0, "entrant2Score": 1.0},
+		map[string]interface{}{"gameNum": 3.0, "winnerId": "1092521", "entrant1Score": 1.0, "entrant2Score": 0.0},
+	}, gameData)
+	assert.Equal(t, "4689059", m.ID)
+	assert.Equal(t, MatchStateComplete, m.State)
+	assert.Equal(t, "1092521", m.WinnerID)
+	assert.Equal(t, "1092522", m.LoserID)
+	assert.Equal(t, 2, m.Player1Score)
+}
+
+func TestReportStartGGDisqualification(t *testing.T) {
+	server := newStartGGTestServer(t, func(operation string, vars map[string]interface{}) string {
+		assert.Equal(t, "ReportSet", operation)
+		assert.Equal(t, true, vars["isDQ"])
+		assert.Nil(t, vars["gameData"])
+		return `{"data":{"reportBracketSet":[` + startGGMutationSet + `]}}`
+	})
+	defer server.Close()
+
+	c := New(WithStartGGToken("token"), WithStartGGEndpoint(server.URL))
+	_, err := c.ReportMatch(context.Background(), "https://www.start.gg/tournament/x/event/y/brackets/1/171722", "4689059", &MatchReport{
+		WinnerID:     "1092521",
+		Disqualified: true,
+	})
+	assert.NoError(t, err)
+}
+
+func TestReportStartGGDisqualificationWithoutWinner(t *testing.T) {
+	server := newStartGGTestServer(t, func(operation string, vars map[string]interface{}) string {
+		t.Errorf("unexpected %s request", operation)
+		return `{}`
+	})
+	defer server.Close()
+
+	c := New(WithStartGGToken("token"), WithStartGGEndpoint(server.URL))
+	_, err := c.ReportMatch(context.Background(), "https://www.start.gg/tournament/x/event/y/brackets/1/171722", "4689059", &MatchReport{
+		Disqualified: true,
+	})
+	assert.EqualError(t, err, "bracket: a disqualification needs a WinnerID")
+}
+
+func TestUpdateStartGGMatch(t *testing.T) {
+	var operations []string
+	server := newStartGGTestServer(t, func(operation string, vars map[string]interface{}) string {
+		operations = append(operations, operation)
+		assert.Equal(t, "4689059", vars["setId"])
+		switch operation {
+		case "UpdateSet":
+			assert.Nil(t, vars["gameData"])
+			return `{"data":{"updateBracketSet":` + startGGMutationSet + `}}`
+		case "MarkSetInProgress":
+			return `{"data":{"markSetInProgress":` + startGGMutationSet + `}}`
+		case "ResetSet":
+			return `{"data":{"resetSet":` + startGGMutationSet + `}}`
+		}
+		t.Fatalf("unexpected operation %s", operation)
+		return ""
+	})
+	defer server.Close()
+
+	url := "https://www.start.gg/tournament/x/event/y/brackets/1/171722"
+	c := New(WithStartGGToken("token"), WithStartGGEndpoint(server.URL))
+	_, err := c.ReportMatch(context.Background(), url, "4689059", &MatchReport{})
+	assert.NoError(t, err)
+	_, err = c.MarkMatchInProgress(context.Background(), url, "4689059")
+	assert.NoError(t, err)
+	_, err = c.ResetMatch(context.Background(), url, "4689059")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"UpdateSet", "MarkSetInProgress", "ResetSet"}, operations)
+}
+
+func TestStartGGMutationErrors(t *testing.T) {
+	requests := 0
+	server := newStartGGTestServer(t, func(operation string, vars map[string]interface{}) string {
+		requests++
+		return `{"errors":[{"message":"Set not found"}]}`
+	})
+	defer server.Close()
+
+	c := New(WithStartGGToken("token"), WithStartGGEndpoint(server.URL))
+	_, err := c.ResetMatch(context.Background(), "https://www.start.gg/tournament/x/event/y/brackets/1/171722", "1")
+	var apiErr *APIError
+	assert.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, []string{"Set not found"}, apiErr.Messages)
+	assert.Equal(t, 1, requests)
+}
+
+func TestSmashGGWithoutTokenCannotReport(t *testing.T) {
+	c := New()
+	_, err := c.ReportMatch(context.Background(), "https://smash.gg/tournament/x/brackets/1/2/171722", "4689059", &MatchReport{WinnerID: "1"})
+	assert.True(t, errors.Is(err, ErrNotSupported))
+}
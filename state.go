@@ -0,0 +1,127 @@
+package bracket
+
+import "strconv"
+
+// BracketState is the normalized state of a bracket. The raw value the
+// service returned is kept in Bracket.RawState.
+type BracketState string
+
+const (
+	// BracketStatePending means the bracket hasn't started.
+	BracketStatePending BracketState = "pending"
+	// BracketStateOpen means the bracket is ready to start.
+	BracketStateOpen BracketState = "open"
+	// BracketStateInProgress means matches are being played.
+	BracketStateInProgress BracketState = "in_progress"
+	// BracketStateAwaitingReview means every match is done but the
+	// organizer hasn't finalized the results.
+	BracketStateAwaitingReview BracketState = "awaiting_review"
+	// BracketStateComplete means the bracket is finished.
+	BracketStateComplete BracketState = "complete"
+	// BracketStateCancelled means the bracket won't be played.
+	BracketStateCancelled BracketState = "cancelled"
+)
+
+// MatchState is the normalized state of a match. The raw value the service
+// returned is kept in Match.RawState.
+type MatchState string
+
+const (
+	// MatchStatePending means the match is waiting on its players.
+	MatchStatePending MatchState = "pending"
+	// MatchStateOpen means both players are known and the match can be
+	// played.
+	MatchStateOpen MatchState = "open"
+	// MatchStateInProgress means the match is being played.
+	MatchStateInProgress MatchState = "in_progress"
+	// MatchStateAwaitingReview means a result has been reported but not
+	// yet confirmed.
+	MatchStateAwaitingReview MatchState = "awaiting_review"
+	// MatchStateComplete means the match has a result.
+	MatchStateComplete MatchState = "complete"
+	// MatchStateCancelled means the match won't be played.
+	MatchStateCancelled MatchState = "cancelled"
+)
+
+// challongeBracketStates maps Challonge tournament states to normalized
+// ones. Unknown states are treated as pending.
+var challongeBracketStates = map[string]BracketState{
+	"pending":                BracketStatePending,
+	"checking_in":            BracketStatePending,
+	"checked_in":             BracketStatePending,
+	"accepting_predictions":  BracketStatePending,
+	"underway":               BracketStateInProgress,
+	"group_stages_underway":  BracketStateInProgress,
+	"group_stages_finalized": BracketStateInProgress,
+	"awaiting_review":        BracketStateAwaitingReview,
+	"complete":               BracketStateComplete,
+}
+
+func convertChallongeBracketState(state string) BracketState {
+	if s, ok := challongeBracketStates[state]; ok {
+		return s
+	}
+	return BracketStatePending
+}
+
+// convertChallongeMatchState converts a Challonge match state. Challonge
+// marks a match as being played with underway_at rather than a state.
+func convertChallongeMatchState(state string, underway bool) MatchState {
+	switch state {
+	case "open":
+		if underway {
+			return MatchStateInProgress
+		}
+		return MatchStateOpen
+	case "complete":
+		return MatchStateComplete
+	}
+	return MatchStatePending
+}
+
+// smash.gg and start.gg activity states.
+const (
+	smashGGStateCreated   = 1
+	smashGGStateActive    = 2
+	smashGGStateCompleted = 3
+	smashGGStateReady     = 4
+	smashGGStateInvalid   = 5
+	smashGGStateCalled    = 6
+	smashGGStateQueued    = 7
+)
+
+func convertSmashGGBracketState(stateNum int) BracketState {
+	switch stateNum {
+	case smashGGStateActive:
+		return BracketStateInProgress
+	case smashGGStateCompleted:
+		return BracketStateComplete
+	case smashGGStateReady:
+		return BracketStateOpen
+	case smashGGStateInvalid:
+		return BracketStateCancelled
+	}
+	return BracketStatePending
+}
+
+// convertSmashGGMatchState converts a set's state. A set that hasn't
+// started is only open once both of its entrants are known.
+func convertSmashGGMatchState(stateNum int, hasEntrants bool) MatchState {
+	switch stateNum {
+	case smashGGStateActive:
+		return MatchStateInProgress
+	case smashGGStateCompleted:
+		return MatchStateComplete
+	case smashGGStateInvalid:
+		return MatchStateCancelled
+	case smashGGStateCreated, smashGGStateReady, smashGGStateCalled, smashGGStateQueued:
+		if hasEntrants {
+			return MatchStateOpen
+		}
+	}
+	return MatchStatePending
+}
+
+func rawSmashGGState(stateNum int) string {
+	return strconv.Itoa(stateNum)
+}
@@ -0,0 +1,42 @@
+package bracket
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvertChallongeBracketState(t *testing.T) {
+	assert.Equal(t, BracketStatePending, convertChallongeBracketState("pending"))
+	assert.Equal(t, BracketStatePending, convertChallongeBracketState("checking_in"))
+	assert.Equal(t, BracketStateInProgress, convertChallongeBracketState("underway"))
+	assert.Equal(t, BracketStateInProgress, convertChallongeBracketState("group_stages_underway"))
+	assert.Equal(t, BracketStateAwaitingReview, convertChallongeBracketState("awaiting_review"))
+	assert.Equal(t, BracketStateComplete, convertChallongeBracketState("complete"))
+	assert.Equal(t, BracketStatePending, convertChallongeBracketState("something_new"))
+}
+
+func TestConvertChallongeMatchState(t *testing.T) {
+	assert.Equal(t, MatchStatePending, convertChallongeMatchState("pending", false))
+	assert.Equal(t, MatchStateOpen, convertChallongeMatchState("open", false))
+	assert.Equal(t, MatchStateInProgress, convertChallongeMatchState("open", true))
+	assert.Equal(t, MatchStateComplete, convertChallongeMatchState("complete", true))
+}
+
+func TestConvertSmashGGBracketState(t *testing.T) {
+	assert.Equal(t, BracketStatePending, convertSmashGGBracketState(1))
+	assert.Equal(t, BracketStateInProgress, convertSmashGGBracketState(2))
+	assert.Equal(t, BracketStateComplete, convertSmashGGBracketState(3))
+	assert.Equal(t, BracketStateOpen, convertSmashGGBracketState(4))
+	assert.Equal(t, BracketStateCancelled, convertSmashGGBracketState(5))
+}
+
+func TestConvertSmashGGMatchState(t *testing.T) {
+	assert.Equal(t, MatchStatePending, convertSmashGGMatchState(1, false))
+	assert.Equal(t, MatchStateOpen, convertSmashGGMatchState(1, true))
+	assert.Equal(t, MatchStateInProgress, convertSmashGGMatchState(2, true))
+	assert.Equal(t, MatchStateComplete, convertSmashGGMatchState(3, true))
+	assert.Equal(t, MatchStateCancelled, convertSmashGGMatchState(5, true))
+	assert.Equal(t, MatchStateOpen, convertSmashGGMatchState(6, true))
+	assert.Equal(t, MatchStatePending, convertSmashGGMatchState(0, true))
+}
@@ -0,0 +1,217 @@
+package bracket
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Tournament is a tournament made up of one or more events.
+type Tournament struct {
+	ID      string
+	Name    string
+	URL     string
+	StartAt *time.Time
+	EndAt   *time.Time
+	// Venue and VenueAddress are empty for online tournaments.
+	Venue        string
+	VenueAddress string
+	// Timezone is the IANA name of the tournament's local time zone, such
+	// as "America/Los_Angeles".
+	Timezone string
+	Events   []*TournamentEvent
+}
+
+// TournamentEvent is a single competition within a tournament, such as a
+// game's singles bracket, played over one or more phases.
+type TournamentEvent struct {
+	ID     string
+	Name   string
+	URL    string
+	Phases []*Phase
+}
+
+// Phase is a stage of an event, such as pools or top 8, split into one or
+// more phase groups that are played at the same time.
+type Phase struct {
+	ID   string
+	Name string
+	// Order is the phase's position in the event, starting at 1.
+	Order  int
+	Groups []*PhaseGroup
+}
+
+// PhaseGroup is a single bracket within a phase, such as one pool.
+type PhaseGroup struct {
+	ID string
+	// Identifier is the group's name within its phase, such as "A3".
+	Identifier string
+	URL        string
+	Bracket    *Bracket
+}
+
+// TournamentFetcher is implemented by providers that can fetch a whole
+// tournament rather than a single bracket.
+type TournamentFetcher interface {
+	// FetchTournament fetches the tournament at url, with every event,
+	// phase and phase group in it.
+	FetchTournament(ctx context.Context, url string) (*Tournament, error)
+	// FetchTournamentEvent fetches the event at url, with every phase
+	// and phase group in it.
+	FetchTournamentEvent(ctx context.Context, url string) (*TournamentEvent, error)
+}
+
+func (c *Client) tournamentFetcher(url string) (TournamentFetcher, error) {
+	p := c.ProviderFor(url)
+	if p == nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedURL, url)
+	}
+	fetcher, ok := p.(TournamentFetcher)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s cannot fetch tournaments", ErrNotSupported, p.Name())
+	}
+	return fetcher, nil
+}
+
+// FetchTournament fetches the tournament at url along with every event,
+// phase and phase group in it. url may link to any page of the tournament.
+// smash.gg tournaments can only be fetched with a start.gg token.
+func (c *Client) FetchTournament(ctx context.Context, url string) (*Tournament, error) {
+	fetcher, err := c.tournamentFetcher(url)
+	if err != nil {
+		return nil, err
+	}
+	return fetcher.FetchTournament(ctx, url)
+}
+
+// FetchTournamentEvent fetches the event at url along with every phase
+// and phase group in it.
+func (c *Client) FetchTournamentEvent(ctx context.Context, url string) (*TournamentEvent, error) {
+	fetcher, err := c.tournamentFetcher(url)
+	if err != nil {
+		return nil, err
+	}
+	return fetcher.FetchTournamentEvent(ctx, url)
+}
+
+// Bracket combines every phase group of the event into a single bracket.
+// Players who play in more than one phase appear once, with their seed
+// from the first phase they played in. Ranks come from the final phase
+// only, since earlier phases rank players within a pool rather than the
+// event, so players knocked out before it have a rank of 0.
+func (e *TournamentEvent) Bracket() *Bracket {
+	b := &Bracket{
+		URL:   e.URL,
+		Name:  e.Name,
+		Event: &TournamentEvent{ID: e.ID, Name: e.Name, URL: e.URL},
+	}
+
+	phases := make([]*Phase, len(e.Phases))
+	copy(phases, e.Phases)
+	sort.SliceStable(phases, func(i, j int) bool {
+		return phases[i].Order < phases[j].Order
+	})
+
+	var final *Phase
+	for _, phase := range phases {
+		for _, g := range phase.Groups {
+			if g.Bracket != nil {
+				final = phase
+			}
+		}
+	}
+
+	players := make(map[string]*Player)
+	var groups []*Bracket
+	var states []BracketState
+	for _, phase := range phases {
+		for _, g := range phase.Groups {
+			if g.Bracket == nil {
+				continue
+			}
+			groups = append(groups, g.Bracket)
+			if b.Tournament == nil {
+				b.Tournament = g.Bracket.Tournament
+			}
+			states = append(states, g.Bracket.State)
+			b.Matches = append(b.Matches, g.Bracket.Matches...)
+			for _, p := range g.Bracket.Players {
+				if linked, ok := players[p.ID]; ok {
+					if phase == final {
+						linked.Rank = p.Rank
+					}
+					continue
+				}
+				linked := *p
+				if phase != final {
+					linked.Rank = 0
+				}
+				players[p.ID] = &linked
+				b.Players = append(b.Players, &linked)
+			}
+			if g.Bracket.StartedAt != nil && (b.StartedAt == nil || g.Bracket.StartedAt.Before(*b.StartedAt)) {
+				b.StartedAt = g.Bracket.StartedAt
+			}
+			if g.Bracket.UpdatedAt != nil && (b.UpdatedAt == nil || g.Bracket.UpdatedAt.After(*b.UpdatedAt)) {
+				b.UpdatedAt = g.Bracket.UpdatedAt
+			}
+		}
+	}
+
+	if b.Tournament != nil {
+		b.Name = bracketName(b.Tournament.Name, e.Name, "", "", 0)
+	}
+	b.State = combinedState(states)
+	if len(groups) == 1 {
+		b.Format = groups[0].Format
+		b.FormatSettings = groups[0].FormatSettings
+	} else if len(groups) > 1 {
+		b.Format = FormatOther
+	}
+	return b
+}
+
+// bracketName joins the names of a bracket's tournament, event and phase,
+// such as "Super Smash Sundays 48 – Melee Singles – Pools A3", skipping any
+// that are empty. The group identifier is only used when the phase has
+// more than one group.
+func bracketName(tournament, event, phase, group string, groupCount int) string {
+	if groupCount > 1 && group != "" {
+		phase = strings.TrimSpace(phase + " " + group)
+	}
+	var parts []string
+	for _, part := range []string{tournament, event, phase} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, " – ")
+}
+
+// combinedState works out the state of a bracket made up of parts in the
+// given states. It is complete once they all are, and in progress once any
+// has started.
+func combinedState(states []BracketState) BracketState {
+	if len(states) == 0 {
+		return BracketStatePending
+	}
+	complete, started := 0, 0
+	for _, s := range states {
+		switch s {
+		case BracketStateComplete:
+			complete++
+			started++
+		case BracketStateInProgress, BracketStateAwaitingReview:
+			started++
+		}
+	}
+	switch {
+	case complete == len(states):
+		return BracketStateComplete
+	case started > 0:
+		return BracketStateInProgress
+	}
+	return BracketStatePending
+}
@@ -0,0 +1,87 @@
+package bracket
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTournamentEventBracket(t *testing.T) {
+	t1 := time.Unix(100, 0)
+	t2 := time.Unix(200, 0)
+	pool := &Bracket{
+		State:     BracketStateComplete,
+		StartedAt: &t1,
+		UpdatedAt: &t1,
+		Format:    FormatDoubleElimination,
+		Players: []*Player{
+			{ID: "1", Name: "Alice", Seed: 1, Rank: 1},
+			{ID: "2", Name: "Bob", Seed: 2, Rank: 2},
+		},
+		Matches: []*Match{{ID: "a", Player1ID: "1", Player2ID: "2"}},
+	}
+	top8 := &Bracket{
+		State:     BracketStateInProgress,
+		StartedAt: &t2,
+		UpdatedAt: &t2,
+		Format:    FormatDoubleElimination,
+		Players: []*Player{
+			{ID: "1", Name: "Alice", Seed: 3},
+			{ID: "3", Name: "Carol", Seed: 1, Rank: 5},
+		},
+		Matches: []*Match{{ID: "b", Player1ID: "1", Player2ID: "3"}},
+	}
+	// phases out of order, to check they're sorted
+	e := &TournamentEvent{
+		Name: "Melee Singles",
+		URL:  "https://www.start.gg/tournament/x/event/melee-singles",
+		Phases: []*Phase{
+			{Name: "Top 8", Order: 2, Groups: []*PhaseGroup{{Bracket: top8}}},
+			{Name: "Pools", Order: 1, Groups: []*PhaseGroup{{Bracket: pool}, {}}},
+		},
+	}
+
+	b := e.Bracket()
+	assert.Equal(t, "Melee Singles", b.Name)
+	assert.Equal(t, e.URL, b.URL)
+	assert.Equal(t, BracketStateInProgress, b.State)
+	assert.Equal(t, FormatOther, b.Format)
+	assert.Equal(t, &t1, b.StartedAt)
+	assert.Equal(t, &t2, b.UpdatedAt)
+	// ranks only come from top 8, so Alice's pool win doesn't count and Bob
+	// has no rank
+	assert.Equal(t, []*Player{
+		{ID: "1", Name: "Alice", Seed: 1},
+		{ID: "2", Name: "Bob", Seed: 2},
+		{ID: "3", Name: "Carol", Seed: 1, Rank: 5},
+	}, b.Players)
+	assert.Len(t, b.Matches, 2)
+
+	// the phase groups' players are left alone
+	assert.Equal(t, 3, top8.Players[0].Seed)
+}
+
+func TestBracketName(t *testing.T) {
+	assert.Equal(t, "Genesis 4 – Melee Singles – Pools A3", bracketName("Genesis 4", "Melee Singles", "Pools", "A3", 16))
+	assert.Equal(t, "Genesis 4 – Melee Singles – Top 8", bracketName("Genesis 4", "Melee Singles", "Top 8", "1", 1))
+	assert.Equal(t, "Genesis 4 – A3", bracketName("Genesis 4", "", "", "A3", 16))
+	assert.Equal(t, "Genesis 4", bracketName("Genesis 4", "", "", "", 0))
+}
+
+func TestCombinedState(t *testing.T) {
+	assert.Equal(t, BracketStatePending, combinedState(nil))
+	assert.Equal(t, BracketStatePending, combinedState([]BracketState{BracketStatePending, BracketStateOpen}))
+	assert.Equal(t, BracketStateInProgress, combinedState([]BracketState{BracketStateComplete, BracketStatePending}))
+	assert.Equal(t, BracketStateComplete, combinedState([]BracketState{BracketStateComplete, BracketStateComplete}))
+}
+
+func TestFetchTournamentUnsupported(t *testing.T) {
+	c := New()
+	_, err := c.FetchTournament(context.Background(), "https://smash.gg/tournament/super-smash-sundays-48")
+	assert.True(t, errors.Is(err, ErrNotSupported))
+	_, err = c.FetchTournamentEvent(context.Background(), "http://example.com")
+	assert.True(t, errors.Is(err, ErrUnsupportedURL))
+}
@@ -0,0 +1,195 @@
+package bracket
+
+import (
+	"context"
+	"time"
+)
+
+// EventType identifies the kind of change an Event describes.
+type EventType int
+
+const (
+	// WatchError means a fetch failed. The watcher keeps polling.
+	WatchError EventType = iota
+	// MatchStarted means a match has started.
+	MatchStarted
+	// ScoreChanged means a match's score changed.
+	ScoreChanged
+	// MatchCompleted means a match has finished.
+	MatchCompleted
+	// PlayerAdvanced means a player was placed into a match, usually by
+	// winning or losing an earlier one. Players already in a match the
+	// first time it's seen don't count.
+	PlayerAdvanced
+	// BracketStateChanged means the bracket's state changed.
+	BracketStateChanged
+	// PlayerRankFinalized means a player's final rank was set.
+	PlayerRankFinalized
+)
+
+func (t EventType) String() string {
+	switch t {
+	case WatchError:
+		return "watch error"
+	case MatchStarted:
+		return "match started"
+	case ScoreChanged:
+		return "score changed"
+	case MatchCompleted:
+		return "match completed"
+	case PlayerAdvanced:
+		return "player advanced"
+	case BracketStateChanged:
+		return "bracket state changed"
+	case PlayerRankFinalized:
+		return "player rank finalized"
+	}
+	return "unknown"
+}
+
+// DefaultWatchInterval is how often Watch polls when given an interval of
+// zero or less.
+const DefaultWatchInterval = 10 * time.Second
+
+// Event describes a change between two consecutive fetches of a bracket.
+type Event struct {
+	Type EventType
+	// Bracket is the snapshot the change was seen in.
+	Bracket *Bracket
+	// Match is the match that changed, for match events and PlayerAdvanced.
+	Match *Match
+	// PreviousMatch is the match as it was in the previous snapshot, or
+	// nil if the match is new.
+	PreviousMatch *Match
+	// Player is the player that advanced or had their rank finalized.
+	Player *Player
+	// PreviousState is the bracket's state before a BracketStateChanged.
+	PreviousState BracketState
+	// Err is the error for WatchError events.
+	Err error
+}
+
+// Watch fetches the bracket at url every interval and sends an event on
+// the returned channel for each change between consecutive fetches. The
+// first fetch only sets a baseline. Failed fetches are sent as WatchError
+// events and don't interrupt polling. The channel is closed once ctx is done.
+// An interval of zero or less polls every DefaultWatchInterval.
+func (c *Client) Watch(ctx context.Context, url string, interval time.Duration) <-chan Event {
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+	events := make(chan Event)
+	go func() {
+		defer close(events)
+
+		var prev *Bracket
+		for {
+			b, err := c.FetchBracketContext(ctx, url)
+			if ctx.Err() != nil {
+				return
+			}
+
+			var batch []Event
+			if err != nil {
+				batch = []Event{{Type: WatchError, Err: err}}
+			} else {
+				if prev != nil {
+					batch = bracketEvents(prev, b)
+				}
+				prev = b
+			}
+			for _, e := range batch {
+				select {
+				case events <- e:
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			if sleep(ctx, interval) != nil {
+				return
+			}
+		}
+	}()
+	return events
+}
+
+// bracketEvents compares two snapshots of a bracket, matching up matches
+// and players by ID, and returns the changes from prev to next.
+func bracketEvents(prev, next *Bracket) []Event {
+	var events []Event
+
+	players := make(map[string]*Player, len(next.Players))
+	for _, p := range next.Players {
+		players[p.ID] = p
+	}
+
+	prevMatches := make(map[string]*Match, len(prev.Matches))
+	for _, m := range prev.Matches {
+		prevMatches[m.ID] = m
+	}
+	for _, m := range next.Matches {
+		old := prevMatches[m.ID]
+		// Without an UpdatedAt to go on, compare every field
+		if old != nil && old.UpdatedAt != nil && sameTime(old.UpdatedAt, m.UpdatedAt) {
+			continue
+		}
+		matchEvent := func(t EventType) Event {
+			return Event{Type: t, Bracket: next, Match: m, PreviousMatch: old}
+		}
+		before := old
+		if before == nil {
+			before = &Match{}
+		}
+
+		if before.StartedAt == nil && m.StartedAt != nil {
+			events = append(events, matchEvent(MatchStarted))
+		}
+		if before.Player1Score != m.Player1Score || before.Player2Score != m.Player2Score {
+			events = append(events, matchEvent(ScoreChanged))
+		}
+		if before.State != MatchStateComplete && m.State == MatchStateComplete {
+			events = append(events, matchEvent(MatchCompleted))
+		}
+		for _, ids := range [][2]string{
+			{before.Player1ID, m.Player1ID},
+			{before.Player2ID, m.Player2ID},
+		} {
+			if old != nil && ids[0] != ids[1] && hasPlayer(ids[1]) {
+				e := matchEvent(PlayerAdvanced)
+				e.Player = players[ids[1]]
+				events = append(events, e)
+			}
+		}
+	}
+
+	prevPlayers := make(map[string]*Player, len(prev.Players))
+	for _, p := range prev.Players {
+		prevPlayers[p.ID] = p
+	}
+	for _, p := range next.Players {
+		old := prevPlayers[p.ID]
+		if p.Rank != 0 && (old == nil || old.Rank != p.Rank) {
+			events = append(events, Event{Type: PlayerRankFinalized, Bracket: next, Player: p})
+		}
+	}
+
+	if prev.State != next.State {
+		events = append(events, Event{Type: BracketStateChanged, Bracket: next, PreviousState: prev.State})
+	}
+
+	return events
+}
+
+// hasPlayer reports whether a match slot holds a player. Empty slots
+// come through from the providers as "0".
+func hasPlayer(id string) bool {
+	return id != "" && id != "0"
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
@@ -0,0 +1,153 @@
+package bracket
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func eventTypes(events []Event) []EventType {
+	types := make([]EventType, len(events))
+	for i, e := range events {
+		types[i] = e.Type
+	}
+	return types
+}
+
+func TestBracketEvents(t *testing.T) {
+	t1 := time.Unix(1, 0)
+	t2 := time.Unix(2, 0)
+	prev := &Bracket{
+		State: BracketStateInProgress,
+		Players: []*Player{
+			{ID: "1", Name: "CDK"},
+			{ID: "2", Name: "Slime"},
+			{ID: "3", Name: "A-Dar"},
+		},
+		Matches: []*Match{
+			{ID: "a", UpdatedAt: &t1, State: MatchStateOpen, Player1ID: "1", Player2ID: "2"},
+			{ID: "b", UpdatedAt: &t1, State: MatchStatePending, Player1ID: "0", Player2ID: "3"},
+		},
+	}
+	next := &Bracket{
+		State: BracketStateComplete,
+		Players: []*Player{
+			{ID: "1", Name: "CDK"},
+			{ID: "2", Name: "Slime", Rank: 3},
+			{ID: "3", Name: "A-Dar"},
+		},
+		Matches: []*Match{
+			{ID: "a", UpdatedAt: &t2, StartedAt: &t2, State: MatchStateComplete, Player1ID: "1", Player2ID: "2", Player1Score: 2},
+			{ID: "b", UpdatedAt: &t2, State: MatchStateOpen, Player1ID: "1", Player2ID: "3"},
+		},
+	}
+
+	events := bracketEvents(prev, next)
+	assert.Equal(t, []EventType{
+		MatchStarted,
+		ScoreChanged,
+		MatchCompleted,
+		PlayerAdvanced,
+		PlayerRankFinalized,
+		BracketStateChanged,
+	}, eventTypes(events))
+
+	assert.Equal(t, "a", events[0].Match.ID)
+	assert.Equal(t, prev.Matches[0], events[0].PreviousMatch)
+	assert.Equal(t, "b", events[3].Match.ID)
+	assert.Equal(t, "CDK", events[3].Player.Name)
+	assert.Equal(t, "Slime", events[4].Player.Name)
+	assert.Equal(t, BracketStateInProgress, events[5].PreviousState)
+	assert.Equal(t, next, events[5].Bracket)
+}
+
+func TestBracketEventsNewMatch(t *testing.T) {
+	prev := &Bracket{}
+	next := &Bracket{
+		Players: []*Player{{ID: "1"}, {ID: "2"}},
+		Matches: []*Match{{ID: "a", State: MatchStateOpen, Player1ID: "1", Player2ID: "2"}},
+	}
+	// the players were already in the match when it appeared
+	assert.Empty(t, bracketEvents(prev, next))
+}
+
+func TestBracketEventsWithoutUpdatedAt(t *testing.T) {
+	prev := &Bracket{Matches: []*Match{{ID: "a", Player1Score: 1}}}
+	next := &Bracket{Matches: []*Match{{ID: "a", Player1Score: 2}}}
+	assert.Equal(t, []EventType{ScoreChanged}, eventTypes(bracketEvents(prev, next)))
+}
+
+func TestBracketEventsSkipsUnchangedMatches(t *testing.T) {
+	t1 := time.Unix(1, 0)
+	prev := &Bracket{Matches: []*Match{{ID: "a", UpdatedAt: &t1, Player1Score: 1}}}
+	// Same UpdatedAt means nothing changed, whatever the other fields say
+	next := &Bracket{Matches: []*Match{{ID: "a", UpdatedAt: &t1, Player1Score: 2}}}
+	assert.Empty(t, bracketEvents(prev, next))
+}
+
+func TestWatch(t *testing.T) {
+	var mu sync.Mutex
+	bodies := []string{
+		`{"entities":{"groups":{"state":1},"sets":[{"id":1,"round":2,"state":1,"updatedAt":1}]}}`,
+		`{"entities":{"groups":{"state":1},"sets":[{"id":1,"round":2,"state":1,"updatedAt":2,"entrant1Score":1}]}}`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if len(bodies) == 0 {
+			http.Error(w, "gone", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(bodies[0]))
+		bodies = bodies[1:]
+	}))
+	defer server.Close()
+
+	c := New(WithSmashGGBaseURL(server.URL))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	events := c.Watch(ctx, "https://smash.gg/tournament/super-smash-sundays-48/brackets/14221/50133/165583", time.Millisecond)
+
+	e := <-events
+	assert.Equal(t, ScoreChanged, e.Type)
+	assert.Equal(t, "1", e.Match.ID)
+	assert.Equal(t, 1, e.Match.Player1Score)
+
+	e = <-events
+	assert.Equal(t, WatchError, e.Type)
+	assert.Error(t, e.Err)
+
+	cancel()
+	for range events {
+	}
+}
+
+func TestWatchDefaultInterval(t *testing.T) {
+	var mu sync.Mutex
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if strings.HasPrefix(r.URL.Path, "/phase_group/") {
+			requests++
+		}
+		w.Write([]byte(`{"entities":{"groups":{"state":1},"sets":[]}}`))
+	}))
+	defer server.Close()
+
+	c := New(WithSmashGGBaseURL(server.URL))
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	for range c.Watch(ctx, "https://smash.gg/tournament/super-smash-sundays-48/brackets/14221/50133/165583", 0) {
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 1, requests)
+}